@@ -0,0 +1,269 @@
+package glog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	genv "github.com/omgolab/go-commons/pkg/env"
+	gfile "github.com/omgolab/go-commons/pkg/file/open"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	FormatConsole = "console"
+	FormatJSON    = "json"
+	// FormatNone disables the stdout output, e.g. to log into the file only
+	FormatNone = "none"
+
+	// DefaultConfigEnvPrefix is the default prefix of the env variables read by ConfigFromEnv
+	DefaultConfigEnvPrefix = "GLOG"
+)
+
+// Config is the serializable logger configuration used by NewFromConfig
+type Config struct {
+	Level            string            `json:"level,omitempty" yaml:"level,omitempty"`
+	Format           string            `json:"format,omitempty" yaml:"format,omitempty"`
+	TimestampFormat  string            `json:"timestampFormat,omitempty" yaml:"timestampFormat,omitempty"`
+	DisableTimestamp bool              `json:"disableTimestamp,omitempty" yaml:"disableTimestamp,omitempty"`
+	CallerLevel      string            `json:"callerLevel,omitempty" yaml:"callerLevel,omitempty"`
+	NamespaceLevels  map[string]string `json:"namespaceLevels,omitempty" yaml:"namespaceLevels,omitempty"`
+	File             FileConfig        `json:"file,omitempty" yaml:"file,omitempty"`
+}
+
+// FileConfig is the file output; the file is rotated when MaxSizeMB is set
+type FileConfig struct {
+	Path       string `json:"path,omitempty" yaml:"path,omitempty"`
+	MaxSizeMB  int    `json:"maxSizeMB,omitempty" yaml:"maxSizeMB,omitempty"`
+	MaxBackups int    `json:"maxBackups,omitempty" yaml:"maxBackups,omitempty"`
+}
+
+// Validate returns all the invalid settings at once
+func (c Config) Validate() error {
+	var errs []error
+	if c.Level != "" {
+		if _, err := ParseLogLevel(c.Level); err != nil {
+			errs = append(errs, fmt.Errorf("level: %w", err))
+		}
+	}
+	if c.CallerLevel != "" {
+		if _, err := ParseLogLevel(c.CallerLevel); err != nil {
+			errs = append(errs, fmt.Errorf("callerLevel: %w", err))
+		}
+	}
+	for ns, level := range c.NamespaceLevels {
+		if ns == "" {
+			errs = append(errs, errors.New("namespaceLevels: empty namespace"))
+		}
+		if _, err := ParseLogLevel(level); err != nil {
+			errs = append(errs, fmt.Errorf("namespaceLevels.%s: %w", ns, err))
+		}
+	}
+
+	switch c.Format {
+	case "", FormatConsole, FormatJSON:
+	case FormatNone:
+		if c.File.Path == "" {
+			errs = append(errs, errors.New("format: none requires a file path"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("format: unknown format %q", c.Format))
+	}
+
+	if c.File.MaxSizeMB < 0 {
+		errs = append(errs, errors.New("file.maxSizeMB: must not be negative"))
+	}
+	if c.File.MaxBackups < 0 {
+		errs = append(errs, errors.New("file.maxBackups: must not be negative"))
+	}
+	if c.File.Path == "" && (c.File.MaxSizeMB > 0 || c.File.MaxBackups > 0) {
+		errs = append(errs, errors.New("file: rotation requires a file path"))
+	}
+	if c.File.Path != "" && c.File.MaxBackups > 0 && c.File.MaxSizeMB == 0 {
+		errs = append(errs, errors.New("file.maxBackups: requires file.maxSizeMB"))
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("glog: invalid config: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// NewFromConfig validates the config and creates the logger, extra options are applied last
+func NewFromConfig(cfg Config, options ...LogOption) (Logger, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	var opts []LogOption
+	switch cfg.Format {
+	case FormatJSON:
+		opts = append(opts, WithJsonStdOut())
+	case FormatNone:
+		opts = append(opts, WithWriters())
+	}
+	if cfg.TimestampFormat != "" {
+		opts = append(opts, WithTimestampFormat(cfg.TimestampFormat))
+	}
+	if cfg.Level != "" {
+		level, _ := ParseLogLevel(cfg.Level)
+		opts = append(opts, WithDefaultLogLevel(level))
+	}
+	if len(cfg.NamespaceLevels) > 0 {
+		levels := make(map[string]LogLevel, len(cfg.NamespaceLevels))
+		for ns, name := range cfg.NamespaceLevels {
+			levels[ns], _ = ParseLogLevel(name)
+		}
+		opts = append(opts, WithNamespaceLevels(levels))
+	}
+	if cfg.File.Path != "" {
+		opts = append(opts, withConfigFile(cfg.File))
+	}
+
+	l, err := New(append(opts, options...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.CallerLevel != "" {
+		level, _ := ParseLogLevel(cfg.CallerLevel)
+		l = l.SetMinCallerAttachLevel(level)
+	}
+	if cfg.DisableTimestamp {
+		l = l.DisableTimestamp()
+	}
+	return l, nil
+}
+
+func withConfigFile(fc FileConfig) LogOption {
+	if fc.MaxSizeMB > 0 {
+		return WithRotatingFileLogger(fc.Path, int64(fc.MaxSizeMB)<<20, fc.MaxBackups)
+	}
+	return func(l *logCfg) error {
+		f, err := gfile.OpenFile(fc.Path)
+		if err != nil {
+			return err
+		}
+		l.sc.writers = append(l.sc.writers, f)
+		return nil
+	}
+}
+
+// ConfigFromJSON decodes a JSON config, unknown keys are reported as errors
+func ConfigFromJSON(r io.Reader) (Config, error) {
+	var cfg Config
+	d := json.NewDecoder(r)
+	d.DisallowUnknownFields()
+	if err := d.Decode(&cfg); err != nil {
+		return cfg, fmt.Errorf("glog: invalid JSON config: %w", err)
+	}
+	return cfg, cfg.Validate()
+}
+
+// ConfigFromYAML decodes a YAML config, unknown keys are reported as errors
+func ConfigFromYAML(r io.Reader) (Config, error) {
+	var cfg Config
+	d := yaml.NewDecoder(r)
+	d.KnownFields(true)
+	if err := d.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+		return cfg, fmt.Errorf("glog: invalid YAML config: %w", err)
+	}
+	return cfg, cfg.Validate()
+}
+
+// LoadConfigFile loads a .json, .yaml or .yml config file
+func LoadConfigFile(path string) (Config, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return ConfigFromJSON(bytes.NewReader(b))
+	case ".yaml", ".yml":
+		return ConfigFromYAML(bytes.NewReader(b))
+	default:
+		return Config{}, fmt.Errorf("glog: unsupported config file %q", path)
+	}
+}
+
+// ConfigFromEnv overrides the base config with the env variables using the prefix
+// (DefaultConfigEnvPrefix if empty): <P>_LEVEL, <P>_FORMAT, <P>_TIMESTAMP_FORMAT,
+// <P>_DISABLE_TIMESTAMP, <P>_CALLER_LEVEL, <P>_LEVELS (e.g. `db=warn,http=debug`),
+// <P>_FILE, <P>_FILE_MAX_SIZE_MB and <P>_FILE_MAX_BACKUPS
+func ConfigFromEnv(prefix string, base Config) (Config, error) {
+	if prefix == "" {
+		prefix = DefaultConfigEnvPrefix
+	}
+	env := func(name string) string {
+		return strings.TrimSpace(genv.Env(prefix+"_"+name, ""))
+	}
+
+	cfg := base
+	var errs []error
+	setStr := func(dst *string, name string) {
+		if v := env(name); v != "" {
+			*dst = v
+		}
+	}
+	setInt := func(dst *int, name string) {
+		v := env(name)
+		if v == "" {
+			return
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s_%s: %w", prefix, name, err))
+			return
+		}
+		*dst = n
+	}
+
+	setStr(&cfg.Level, "LEVEL")
+	setStr(&cfg.Format, "FORMAT")
+	setStr(&cfg.TimestampFormat, "TIMESTAMP_FORMAT")
+	setStr(&cfg.CallerLevel, "CALLER_LEVEL")
+	setStr(&cfg.File.Path, "FILE")
+	setInt(&cfg.File.MaxSizeMB, "FILE_MAX_SIZE_MB")
+	setInt(&cfg.File.MaxBackups, "FILE_MAX_BACKUPS")
+
+	if v := env("DISABLE_TIMESTAMP"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s_DISABLE_TIMESTAMP: %w", prefix, err))
+		} else {
+			cfg.DisableTimestamp = b
+		}
+	}
+
+	if v := env("LEVELS"); v != "" {
+		levels, err := ParseNamespaceLevels(v)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s_LEVELS: %w", prefix, err))
+		}
+		nsLevels := make(map[string]string, len(cfg.NamespaceLevels)+len(levels))
+		for ns, level := range cfg.NamespaceLevels {
+			nsLevels[ns] = level
+		}
+		for ns, level := range levels {
+			if ns == "" {
+				cfg.Level = level.String()
+				continue
+			}
+			nsLevels[ns] = level.String()
+		}
+		cfg.NamespaceLevels = nsLevels
+	}
+
+	if len(errs) > 0 {
+		return cfg, fmt.Errorf("glog: invalid env config: %w", errors.Join(errs...))
+	}
+	return cfg, cfg.Validate()
+}
@@ -0,0 +1,184 @@
+package glog_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	glog "github.com/omgolab/go-commons/pkg/log"
+)
+
+func TestConfigFromYAML_NewFromConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	cfg, err := glog.ConfigFromYAML(strings.NewReader(`
+level: info
+format: none
+namespaceLevels:
+  db: error
+file:
+  path: ` + path + `
+  maxSizeMB: 1
+  maxBackups: 2
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := glog.NewFromConfig(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Debug("hidden")
+	l.Info("visible")
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(b), "hidden") || !strings.Contains(string(b), `"message":"visible"`) {
+		t.Errorf("unexpected file output: %s", b)
+	}
+	if got := l.GetNamespaceLogLevels()["db"]; got != glog.ErrorLevel {
+		t.Errorf("expected the db namespace level to be error, got %v", got)
+	}
+}
+
+func TestConfig_ValidationErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		load func() (glog.Config, error)
+		want []string
+	}{
+		{
+			name: "invalid values are all reported",
+			load: func() (glog.Config, error) {
+				return glog.ConfigFromJSON(strings.NewReader(`{"level":"loud","format":"xml","file":{"maxSizeMB":-1,"maxBackups":1}}`))
+			},
+			want: []string{"level", "format", "file.maxSizeMB", "rotation requires a file path"},
+		},
+		{
+			name: "unknown keys are rejected",
+			load: func() (glog.Config, error) {
+				return glog.ConfigFromYAML(strings.NewReader("levle: info\n"))
+			},
+			want: []string{"levle"},
+		},
+		{
+			name: "invalid env values are rejected",
+			load: func() (glog.Config, error) {
+				t.Setenv("APP_FILE_MAX_BACKUPS", "many")
+				t.Setenv("APP_LEVELS", "db=nope")
+				return glog.ConfigFromEnv("APP", glog.Config{})
+			},
+			want: []string{"APP_FILE_MAX_BACKUPS", "APP_LEVELS"},
+		},
+		{
+			name: "backups without a size are rejected",
+			load: func() (glog.Config, error) {
+				return glog.ConfigFromJSON(strings.NewReader(`{"file":{"path":"app.log","maxBackups":3}}`))
+			},
+			want: []string{"file.maxBackups: requires file.maxSizeMB"},
+		},
+		{
+			name: "invalid env booleans are rejected",
+			load: func() (glog.Config, error) {
+				t.Setenv("APP_DISABLE_TIMESTAMP", "maybe")
+				cfg, err := glog.ConfigFromEnv("APP", glog.Config{DisableTimestamp: true})
+				if !cfg.DisableTimestamp {
+					t.Error("expected the base value to be kept")
+				}
+				return cfg, err
+			},
+			want: []string{"APP_DISABLE_TIMESTAMP"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.load()
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			for _, w := range tt.want {
+				if !strings.Contains(err.Error(), w) {
+					t.Errorf("expected %q in %v", w, err)
+				}
+			}
+		})
+	}
+}
+
+func TestConfigFromEnv_OverridesBase(t *testing.T) {
+	t.Setenv("GLOG_FORMAT", "json")
+	t.Setenv("GLOG_LEVELS", "warn,http=debug")
+	t.Setenv("GLOG_DISABLE_TIMESTAMP", "true")
+
+	cfg, err := glog.ConfigFromEnv("", glog.Config{Level: "info", NamespaceLevels: map[string]string{"db": "error"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Format != glog.FormatJSON || cfg.Level != "warn" || !cfg.DisableTimestamp ||
+		cfg.NamespaceLevels["db"] != "error" || cfg.NamespaceLevels["http"] != "debug" {
+		t.Errorf("unexpected config: %+v", cfg)
+	}
+}
+
+func TestRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "r.log")
+	rf, err := glog.NewRotatingFile(path, 10, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rf.Close()
+
+	for _, s := range []string{"aaaaaaaa\n", "bbbbbbbb\n", "cccccccc\n", "dddddddd\n"} {
+		if _, err := rf.Write([]byte(s)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for suffix, want := range map[string]string{"": "dddddddd\n", ".1": "cccccccc\n", ".2": "bbbbbbbb\n"} {
+		b, err := os.ReadFile(path + suffix)
+		if err != nil || string(b) != want {
+			t.Errorf("%s: expected %q, got %q (%v)", path+suffix, want, b, err)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("expected at most 2 backups")
+	}
+}
+
+func TestRotatingFile_RecoversFromFailedRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a.log")
+	rf, err := glog.NewRotatingFile(path, 10, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rf.Close()
+
+	// a non-empty directory makes the rename to a.log.1 fail
+	if err := os.MkdirAll(filepath.Join(path+".1", "x"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rf.Write([]byte("aaaaaaaa\n")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rf.Write([]byte("bbbbbbbb\n")); err == nil {
+		t.Error("expected the rotation error")
+	}
+
+	// the event went to the reopened file and the rotation works once the obstacle is gone
+	if err := os.RemoveAll(path + ".1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rf.Write([]byte("cccccccc\n")); err != nil {
+		t.Fatal(err)
+	}
+	for suffix, want := range map[string]string{"": "cccccccc\n", ".1": "aaaaaaaa\nbbbbbbbb\n"} {
+		b, err := os.ReadFile(path + suffix)
+		if err != nil || string(b) != want {
+			t.Errorf("%s: expected %q, got %q (%v)", path+suffix, want, b, err)
+		}
+	}
+}
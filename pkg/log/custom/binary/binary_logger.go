@@ -0,0 +1,254 @@
+package gbinlog
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	log "github.com/omgolab/go-commons/pkg/log"
+	filter "github.com/omgolab/go-commons/pkg/log/custom"
+	"golang.org/x/exp/slices"
+)
+
+// The file starts with Magic, the column count and the column names, followed
+// by blocks of rows stored column by column:
+//
+//	header: "GLCB" | version byte | uvarint columns | columns * (uvarint len | name)
+//	block:  uvarint rows | columns * rows * (uvarint len | value)
+//
+// Storing the values of a column together keeps the similar values close,
+// which compresses well and lets a reader skip the columns it doesn't need.
+const (
+	Magic   = "GLCB"
+	Version = 1
+
+	// DefaultBlockRows is the number of rows buffered before a block is written
+	DefaultBlockRows = 1024
+	// MaxBlockRows is the largest block, the readers reject the larger row counts as corrupted
+	MaxBlockRows = 1 << 20
+)
+
+type BinLogger interface {
+	filter.FilterLogger
+	// Bin writes a row, the extra values are joined with the delimiter into the last column
+	Bin(str ...string)
+	BinErr(err error, str ...string)
+	// Flush writes the buffered rows as a block
+	Flush() error
+	// Close writes the buffered rows and closes the writer if it's an io.Closer
+	Close() error
+}
+
+type binCfg struct {
+	filter.FilterLogger
+	out       io.Writer
+	w         *bufio.Writer
+	headers   []string
+	blockRows int
+
+	mu      sync.Mutex
+	columns [][]string
+	rows    int
+}
+
+type BinOption func(*binCfg) error
+
+// WithBlockRows sets the number of rows buffered before a block is written (default: DefaultBlockRows)
+func WithBlockRows(n int) BinOption {
+	return func(c *binCfg) error {
+		if n <= 0 || n > MaxBlockRows {
+			return fmt.Errorf("gbinlog: the block rows must be within 1 and %d", MaxBlockRows)
+		}
+		c.blockRows = n
+		return nil
+	}
+}
+
+func (c *binCfg) Bin(str ...string) {
+	c.bin(log.DebugLevel, nil, str)
+}
+
+func (c *binCfg) BinErr(err error, str ...string) {
+	c.bin(log.ErrorLevel, err, str)
+}
+
+func (c *binCfg) bin(level log.LogLevel, err error, values []string) {
+	filter.TagRow(c, level, err, 4, filter.JoinExtraValues(values, len(c.headers), c.GetDelimiter()))
+}
+
+// WriteRecord implements filter.RecordWriter and buffers the record until the block is full
+func (c *binCfg) WriteRecord(r *filter.Record) error {
+	row := filter.Row(c, r, len(c.headers))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, v := range row {
+		c.columns[i] = append(c.columns[i], v)
+	}
+	c.rows++
+
+	if c.rows < c.blockRows {
+		return nil
+	}
+	return c.flushLocked()
+}
+
+func (c *binCfg) Flush() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.flushLocked()
+}
+
+func (c *binCfg) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	err := c.flushLocked()
+	if cl, ok := c.out.(io.Closer); ok {
+		err = errors.Join(err, cl.Close())
+	}
+	return err
+}
+
+func (c *binCfg) flushLocked() error {
+	if c.rows == 0 {
+		return c.w.Flush()
+	}
+
+	writeUvarint(c.w, uint64(c.rows))
+	for i, values := range c.columns {
+		for _, v := range values {
+			writeString(c.w, v)
+		}
+		c.columns[i] = values[:0]
+	}
+	c.rows = 0
+	return c.w.Flush()
+}
+
+// New creates a dual logger writing its tagged events to w as columnar blocks
+// and the others to the console output. Call Flush or Close to write the last rows.
+// A non-empty file is appended to if it's readable and has the same columns,
+// the other non-empty files are rejected.
+func New(w io.Writer, headers []string, opts []BinOption, filterOpts []filter.FilterOption, logOpts ...log.LogOption) (BinLogger, error) {
+	if w == nil {
+		return nil, errors.New("gbinlog: writer is nil")
+	}
+
+	c := &binCfg{out: w, w: bufio.NewWriter(w), headers: headers, blockRows: DefaultBlockRows}
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return nil, err
+		}
+	}
+
+	var err error
+	c.FilterLogger, err = filter.NewRecordLogger("bin", c, filterOpts, logOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	columns := filter.Columns(c, headers)
+	c.columns = make([][]string, len(columns))
+
+	size, err := targetSize(w)
+	if err != nil {
+		return nil, err
+	}
+	if size > 0 {
+		if err := checkAppendable(w, size, columns); err != nil {
+			return nil, err
+		}
+		return c, nil
+	}
+
+	c.w.WriteString(Magic)
+	c.w.WriteByte(Version)
+	writeUvarint(c.w, uint64(len(columns)))
+	for _, name := range columns {
+		writeString(c.w, name)
+	}
+	if err := c.w.Flush(); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// targetSize returns the size of a file target, 0 for the other writers
+func targetSize(w io.Writer) (int64, error) {
+	f, ok := w.(interface{ Stat() (os.FileInfo, error) })
+	if !ok {
+		return 0, nil
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	if !fi.Mode().IsRegular() {
+		return 0, nil
+	}
+	return fi.Size(), nil
+}
+
+// checkAppendable verifies the existing file has the same columns, writing a second header would corrupt it.
+// A partial last block (e.g. a crash while flushing) is truncated so the new blocks stay readable.
+func checkAppendable(w io.Writer, size int64, columns []string) error {
+	ra, ok := w.(io.ReaderAt)
+	if !ok {
+		return errors.New("gbinlog: the target is not empty")
+	}
+	r, err := NewReader(io.NewSectionReader(ra, 0, size))
+	if err != nil {
+		return fmt.Errorf("gbinlog: the target is not empty: %w", err)
+	}
+	if !slices.Equal(r.Columns(), columns) {
+		return fmt.Errorf("gbinlog: the target has other columns %q", r.Columns())
+	}
+
+	end, err := lastBlockEnd(r)
+	if err != nil {
+		t, ok := w.(interface{ Truncate(size int64) error })
+		if !ok {
+			return fmt.Errorf("gbinlog: the target ends with a partial block: %w", err)
+		}
+		if err := t.Truncate(end); err != nil {
+			return err
+		}
+	}
+	if f, ok := w.(*os.File); ok {
+		// the blocks must go after the existing ones
+		if _, err := f.Seek(0, io.SeekEnd); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lastBlockEnd reads the blocks and returns the end of the last complete one,
+// with the error of the partial block following it if any
+func lastBlockEnd(r *Reader) (int64, error) {
+	for {
+		end := r.offset()
+		if _, err := r.ReadBlock(); err != nil {
+			if errors.Is(err, io.EOF) {
+				return end, nil
+			}
+			return end, err
+		}
+	}
+}
+
+// the bufio.Writer keeps the first error and returns it from Flush
+func writeUvarint(w *bufio.Writer, v uint64) {
+	var b [binary.MaxVarintLen64]byte
+	w.Write(b[:binary.PutUvarint(b[:], v)])
+}
+
+func writeString(w *bufio.Writer, s string) {
+	writeUvarint(w, uint64(len(s)))
+	w.WriteString(s)
+}
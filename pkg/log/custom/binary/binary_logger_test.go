@@ -0,0 +1,126 @@
+package gbinlog_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/tj/assert"
+
+	gbinlog "github.com/omgolab/go-commons/pkg/log/custom/binary"
+)
+
+func TestBinLogger(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l, err := gbinlog.New(buf, []string{"Name", "Note"}, []gbinlog.BinOption{gbinlog.WithBlockRows(2)}, nil)
+	assert.NoError(t, err)
+
+	l.Bin("a", "x,y")
+	l.BinErr(errors.New("boom"), "b")
+	l.Bin("c", "line\nbreak", "more")
+	l.Info("not tagged")
+	assert.NoError(t, l.Flush())
+
+	r, err := gbinlog.NewReader(bytes.NewReader(buf.Bytes()))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Timestamp", "Level", "Caller", "Name", "Note", "Error"}, r.Columns())
+
+	block, err := r.ReadBlock()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, block[3])
+	assert.Equal(t, []string{"", "boom"}, block[5])
+
+	recs, err := r.ReadAll()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(recs))
+	assert.Equal(t, "line\nbreak,more", recs[0]["Note"])
+	assert.Equal(t, "DEB", recs[0]["Level"])
+}
+
+func TestBinLogger_Corrupted(t *testing.T) {
+	_, err := gbinlog.NewReader(bytes.NewReader([]byte("CSV,")))
+	assert.Error(t, err)
+
+	buf := &bytes.Buffer{}
+	l, _ := gbinlog.New(buf, []string{"Name"}, nil, nil)
+	l.Bin("a")
+	assert.NoError(t, l.Flush())
+
+	r, err := gbinlog.NewReader(bytes.NewReader(buf.Bytes()[:buf.Len()-1]))
+	assert.NoError(t, err)
+	_, err = r.ReadAll()
+	assert.Error(t, err)
+
+	// the corrupted counts are rejected rather than allocated
+	header := append([]byte(gbinlog.Magic), gbinlog.Version)
+	_, err = gbinlog.NewReader(bytes.NewReader(append(header, 0xff, 0xff, 0xff, 0xff, 0x7f)))
+	assert.Error(t, err)
+
+	rows := append(append(header, 1, 1, 'A'), 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f)
+	r, err = gbinlog.NewReader(bytes.NewReader(rows))
+	assert.NoError(t, err)
+	_, err = r.ReadAll()
+	assert.Error(t, err)
+}
+
+func TestBinLogger_ReuseFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rows.bin")
+	open := func(flag int) *os.File {
+		f, err := os.OpenFile(path, flag|os.O_CREATE, 0644)
+		assert.NoError(t, err)
+		return f
+	}
+
+	// Close writes the buffered rows
+	l, err := gbinlog.New(open(os.O_RDWR), []string{"Name"}, nil, nil)
+	assert.NoError(t, err)
+	l.Bin("a")
+	assert.NoError(t, l.Close())
+
+	// the same columns are appended without a second header
+	l, err = gbinlog.New(open(os.O_RDWR), []string{"Name"}, nil, nil)
+	assert.NoError(t, err)
+	l.Bin("b")
+	assert.NoError(t, l.Close())
+
+	f := open(os.O_RDONLY)
+	defer f.Close()
+	r, err := gbinlog.NewReader(f)
+	assert.NoError(t, err)
+	recs, err := r.ReadAll()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(recs))
+	assert.Equal(t, "a", recs[0]["Name"])
+	assert.Equal(t, "b", recs[1]["Name"])
+
+	// a partial block left by a crash is truncated before appending
+	partial := open(os.O_RDWR | os.O_APPEND)
+	_, err = partial.Write([]byte{2, 1, 'x'})
+	assert.NoError(t, err)
+	l, err = gbinlog.New(partial, []string{"Name"}, nil, nil)
+	assert.NoError(t, err)
+	l.Bin("c")
+	assert.NoError(t, l.Close())
+
+	f2 := open(os.O_RDONLY)
+	defer f2.Close()
+	r, err = gbinlog.NewReader(f2)
+	assert.NoError(t, err)
+	recs, err = r.ReadAll()
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(recs))
+	assert.Equal(t, "c", recs[2]["Name"])
+
+	// other columns or an unreadable file are rejected
+	other := open(os.O_RDWR)
+	defer other.Close()
+	_, err = gbinlog.New(other, []string{"Other"}, nil, nil)
+	assert.Error(t, err)
+
+	writeOnly := open(os.O_WRONLY)
+	defer writeOnly.Close()
+	_, err = gbinlog.New(writeOnly, []string{"Name"}, nil, nil)
+	assert.Error(t, err)
+}
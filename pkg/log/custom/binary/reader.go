@@ -0,0 +1,156 @@
+package gbinlog
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// the limits guard against allocating for a corrupted length or count
+const (
+	maxValueLen = 1 << 26
+	maxColumns  = 1 << 16
+)
+
+// Record is a row keyed by the column names
+type Record map[string]string
+
+// Reader parses the columnar blocks written by the binary logger
+type Reader struct {
+	r       *countingReader
+	columns []string
+}
+
+// countingReader counts the bytes read, i.e. the offset in the file
+type countingReader struct {
+	br *bufio.Reader
+	n  int64
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.br.Read(p)
+	cr.n += int64(n)
+	return n, err
+}
+
+func (cr *countingReader) ReadByte() (byte, error) {
+	b, err := cr.br.ReadByte()
+	if err == nil {
+		cr.n++
+	}
+	return b, err
+}
+
+// NewReader reads and validates the file header
+func NewReader(r io.Reader) (*Reader, error) {
+	br := &countingReader{br: bufio.NewReader(r)}
+
+	magic := make([]byte, len(Magic)+1)
+	if _, err := io.ReadFull(br, magic); err != nil {
+		return nil, fmt.Errorf("gbinlog: read header: %w", err)
+	}
+	if string(magic[:len(Magic)]) != Magic {
+		return nil, errors.New("gbinlog: not a binary log")
+	}
+	if magic[len(Magic)] != Version {
+		return nil, fmt.Errorf("gbinlog: unsupported version %d", magic[len(Magic)])
+	}
+
+	n, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, fmt.Errorf("gbinlog: read header: %w", err)
+	}
+	if n > maxColumns {
+		return nil, fmt.Errorf("gbinlog: read header: column count %d is too large", n)
+	}
+
+	rd := &Reader{r: br}
+	for i := uint64(0); i < n; i++ {
+		name, err := rd.readString()
+		if err != nil {
+			return nil, fmt.Errorf("gbinlog: read header: %w", err)
+		}
+		rd.columns = append(rd.columns, name)
+	}
+	return rd, nil
+}
+
+// offset returns the bytes read so far, the end of the last block read
+func (r *Reader) offset() int64 {
+	return r.r.n
+}
+
+// Columns returns the column names
+func (r *Reader) Columns() []string {
+	return r.columns
+}
+
+// ReadBlock returns the values of the next block column by column or io.EOF at the end
+func (r *Reader) ReadBlock() ([][]string, error) {
+	rows, err := binary.ReadUvarint(r.r)
+	if err != nil {
+		// a clean end is only possible between the blocks
+		return nil, err
+	}
+	if rows > MaxBlockRows {
+		return nil, fmt.Errorf("gbinlog: read block: row count %d is too large", rows)
+	}
+
+	block := make([][]string, len(r.columns))
+	for i := range block {
+		block[i] = make([]string, rows)
+		for j := range block[i] {
+			if block[i][j], err = r.readString(); err != nil {
+				return nil, fmt.Errorf("gbinlog: read block: %w", noEOF(err))
+			}
+		}
+	}
+	return block, nil
+}
+
+// ReadAll returns the rows of all the remaining blocks
+func (r *Reader) ReadAll() ([]Record, error) {
+	var recs []Record
+	for {
+		block, err := r.ReadBlock()
+		if errors.Is(err, io.EOF) {
+			return recs, nil
+		}
+		if err != nil {
+			return recs, err
+		}
+
+		for j := 0; len(block) > 0 && j < len(block[0]); j++ {
+			rec := make(Record, len(r.columns))
+			for i, name := range r.columns {
+				rec[name] = block[i][j]
+			}
+			recs = append(recs, rec)
+		}
+	}
+}
+
+func (r *Reader) readString() (string, error) {
+	n, err := binary.ReadUvarint(r.r)
+	if err != nil {
+		return "", noEOF(err)
+	}
+	if n > maxValueLen {
+		return "", fmt.Errorf("value length %d is too large", n)
+	}
+
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r.r, b); err != nil {
+		return "", noEOF(err)
+	}
+	return string(b), nil
+}
+
+func noEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,160 @@
+package gcustomlog
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/rs/zerolog"
+	"golang.org/x/exp/slices"
+)
+
+// FilterBuilder builds the filter options fluently. The configuration is validated
+// as it's built, the errors are returned by Build or, from Options, by New.
+type FilterBuilder struct {
+	opts      []FilterOption
+	delimiter *rune
+	parts     []string
+	extras    []string
+	errs      []error
+}
+
+func NewFilterBuilder() *FilterBuilder {
+	return &FilterBuilder{}
+}
+
+func (b *FilterBuilder) fail(format string, args ...any) *FilterBuilder {
+	b.errs = append(b.errs, fmt.Errorf("gcustomlog: "+format, args...))
+	return b
+}
+
+func (b *FilterBuilder) with(opt FilterOption) *FilterBuilder {
+	b.opts = append(b.opts, opt)
+	return b
+}
+
+// Delimiter sets the delimiter, setting a different one twice is an error
+func (b *FilterBuilder) Delimiter(d rune) *FilterBuilder {
+	if err := validateDelimiter(d); err != nil {
+		b.errs = append(b.errs, err)
+		return b
+	}
+	if b.delimiter != nil && *b.delimiter != d {
+		return b.fail("conflicting delimiters %q and %q", *b.delimiter, d)
+	}
+	b.delimiter = &d
+	return b
+}
+
+// TimestampFormat sets the layout the timestamps are parsed with, see WithTimestampFormatter
+func (b *FilterBuilder) TimestampFormat(layout string) *FilterBuilder {
+	return b.with(WithTimestampFormatter(layout))
+}
+
+func (b *FilterBuilder) LevelFormatter(f zerolog.Formatter) *FilterBuilder {
+	return b.with(WithLevelFormatter(f))
+}
+
+func (b *FilterBuilder) MessageFormatter(f zerolog.Formatter) *FilterBuilder {
+	return b.with(WithMessageFormatter(f))
+}
+
+func (b *FilterBuilder) CallerFormatter(f zerolog.Formatter) *FilterBuilder {
+	return b.with(WithCallerFormatter(f))
+}
+
+func (b *FilterBuilder) FieldNameFormatter(f zerolog.Formatter) *FilterBuilder {
+	return b.with(WithFieldNameFormatter(f))
+}
+
+func (b *FilterBuilder) FieldValueFormatter(f zerolog.Formatter) *FilterBuilder {
+	return b.with(WithFieldValueFormatter(f))
+}
+
+func (b *FilterBuilder) ErrFieldNameFormatter(f zerolog.Formatter) *FilterBuilder {
+	return b.with(WithErrFieldNameFormatter(f))
+}
+
+func (b *FilterBuilder) ErrFieldValueFormatter(f zerolog.Formatter) *FilterBuilder {
+	return b.with(WithErrFieldValueFormatter(f))
+}
+
+// PartsOrder replaces the default parts order. The names must be zerolog's timestamp,
+// level, caller and message field names or extra columns, each listed once.
+func (b *FilterBuilder) PartsOrder(parts ...string) *FilterBuilder {
+	if b.parts != nil {
+		return b.fail("parts order set twice")
+	}
+	if len(parts) == 0 {
+		return b.fail("empty parts order")
+	}
+	b.parts = slices.Clone(parts)
+	return b
+}
+
+// ExtraColumns renders the fields as parts, see WithExtraColumns
+func (b *FilterBuilder) ExtraColumns(names ...string) *FilterBuilder {
+	b.extras = append(b.extras, names...)
+	return b
+}
+
+func (b *FilterBuilder) RecordLines() *FilterBuilder {
+	return b.with(WithRecordLines())
+}
+
+// Build validates the configuration and returns the filter options
+func (b *FilterBuilder) Build() ([]FilterOption, error) {
+	// validate against a scratch filter writer so the rules match New's
+	fw := &filterWriter{partsOrder: b.parts}
+	if fw.partsOrder == nil {
+		fw.partsOrder = defaultPartsOrder()
+	}
+
+	errs := slices.Clone(b.errs)
+	for _, name := range b.extras {
+		if err := fw.addExtraColumn(name); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if b.parts != nil {
+		for _, p := range b.parts {
+			if !isStandardPart(p) && !slices.Contains(b.extras, p) {
+				errs = append(errs, fmt.Errorf("gcustomlog: unknown part %q", p))
+			}
+		}
+		// an explicit order must place the extra columns itself
+		for _, name := range b.extras {
+			if !slices.Contains(b.parts, name) {
+				errs = append(errs, fmt.Errorf("gcustomlog: extra column %q is missing from the parts order", name))
+			}
+		}
+	}
+	if len(errs) == 0 {
+		if err := fw.validatePartsOrder(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	var opts []FilterOption
+	if b.delimiter != nil {
+		opts = append(opts, WithDelimiter(*b.delimiter))
+	}
+	if b.parts != nil {
+		opts = append(opts, withExactPartsOrder(b.parts))
+	}
+	if len(b.extras) > 0 {
+		opts = append(opts, WithExtraColumns(b.extras...))
+	}
+	return append(opts, b.opts...), nil
+}
+
+// Options returns the filter options, an invalid configuration makes New fail with the Build error
+func (b *FilterBuilder) Options() []FilterOption {
+	opts, err := b.Build()
+	if err != nil {
+		return []FilterOption{func(*filterWriter) error { return err }}
+	}
+	return opts
+}
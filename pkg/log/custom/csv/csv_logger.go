@@ -1,11 +1,17 @@
 package gcsvlog
 
 import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
 	"io/fs"
 	"os"
 	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
 
-	file "github.com/omgolab/go-commons/pkg/file"
 	fo "github.com/omgolab/go-commons/pkg/file/open"
 	log "github.com/omgolab/go-commons/pkg/log"
 	filter "github.com/omgolab/go-commons/pkg/log/custom"
@@ -14,34 +20,132 @@ import (
 type CsvLogger interface {
 	filter.FilterLogger
 	FileName() string
+	Close() error
+	// Csv writes a row, the extra values are joined with the delimiter into the last column
 	Csv(str ...string)
 	CsvErr(err error, str ...string)
+	CsvStruct(v any) error
+	CsvMap(m map[string]any) error
 }
 
 type csvCfg struct {
 	filter.FilterLogger
 	file                     *os.File
 	truncateOnHeadersMissing bool
+	migrateOnHeadersMismatch bool
+
+	mu      sync.Mutex
+	w       *csv.Writer
+	headers []string
+	columns []string
+	comma   rune
+	size    int64
+	rows    int
+	rotation
+
+	timeFormat     string
+	floatFormat    byte
+	floatPrecision int
+	nilValue       string
+
+	bom           bool
+	crlf          bool
+	formulaEscape bool
+	isoTimestamps bool
 }
 
 func (c *csvCfg) FileName() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	return c.file.Name()
 }
 
+// Close waits for the background compressions and closes the file,
+// the rows logged afterwards are lost
+func (c *csvCfg) Close() error {
+	c.compressing.Wait()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.file.Close()
+}
+
 func (c *csvCfg) Csv(str ...string) {
-	// this is a special string to indicate csv write
-	c.TagLog(strings.Join(str, c.GetDelimiter()), log.DebugLevel, nil, 3)
+	c.csv(log.DebugLevel, nil, str)
 }
 
 func (c *csvCfg) CsvErr(err error, str ...string) {
-	// this is a special string to indicate csv write
-	c.TagLog(strings.Join(str, c.GetDelimiter()), log.ErrorLevel, err, 3)
+	c.csv(log.ErrorLevel, err, str)
+}
+
+func (c *csvCfg) csv(level log.LogLevel, err error, values []string) {
+	filter.TagRow(c, level, err, 4, filter.JoinExtraValues(values, len(c.headers), string(c.comma)))
+}
+
+// WriteRecord implements filter.RecordWriter and writes the record as a csv row,
+// the missing values are left empty
+func (c *csvCfg) WriteRecord(r *filter.Record) error {
+	if c.isoTimestamps && !r.Time.IsZero() {
+		r.Timestamp = r.Time.Format(time.RFC3339)
+	}
+
+	row := filter.Row(c, r, len(c.headers))
+	if c.formulaEscape {
+		for i, v := range row {
+			row[i] = escapeFormula(v)
+		}
+	}
+	return c.writeRow(row)
+}
+
+// escapeFormula prefixes the values read as a formula by the spreadsheet tools with a quote
+func escapeFormula(v string) string {
+	if v != "" && strings.ContainsRune("=+-@\t\r", rune(v[0])) {
+		return "'" + v
+	}
+	return v
+}
+
+func (c *csvCfg) writeRow(row []string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// the row still goes to the reopened file when the rotation fails
+	var rerr error
+	if c.shouldRotateLocked() {
+		rerr = c.rotateLocked()
+	}
+
+	if err := c.writeRowLocked(row); err != nil {
+		return errors.Join(rerr, err)
+	}
+	c.rows++
+	return rerr
+}
+
+func (c *csvCfg) writeRowLocked(row []string) error {
+	if err := c.w.Write(row); err != nil {
+		return err
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// Write implements io.Writer for the csv writer to track the file size
+func (c *csvCfg) Write(b []byte) (int, error) {
+	n, err := c.file.Write(b)
+	c.size += int64(n)
+	return n, err
 }
 
 // New creates a dual logger with csv and console output
 func New(csvPath string, headers []string, opts []CsvOption, filterOpts []filter.FilterOption, logOpts ...log.LogOption) (CsvLogger, error) {
 	// create a csv c
-	c := &csvCfg{}
+	c := &csvCfg{
+		headers:        headers,
+		timeFormat:     time.RFC3339,
+		floatFormat:    'g',
+		floatPrecision: -1,
+	}
 
 	// update the options
 	var err error
@@ -52,90 +156,121 @@ func New(csvPath string, headers []string, opts []CsvOption, filterOpts []filter
 		}
 	}
 
-	// set a default csv path
-	c.file, err = c.getCsvFile(csvPath, headers)
-	if err != nil {
-		return nil, err
+	if c.truncateOnHeadersMissing && c.migrateOnHeadersMismatch {
+		return nil, errors.New("gcsvlog: the truncate and migrate modes are exclusive")
 	}
 
 	// update the filter logger
-	c.FilterLogger, err = filter.New("csv", c.file, filterOpts, logOpts...)
+	c.FilterLogger, err = filter.NewRecordLogger("csv", c, filterOpts, logOpts...)
 	if err != nil {
 		return nil, err
 	}
 
-	// write headers
-	err = c.writeCsvHeaders(headers)
+	// WithComma overrides the filter delimiter
+	if c.comma == 0 {
+		comma, _ := utf8.DecodeRuneInString(c.GetDelimiter())
+		if !validDelimiter(comma) {
+			return nil, fmt.Errorf("gcsvlog: invalid delimiter %q", c.GetDelimiter())
+		}
+		c.comma = comma
+	}
 
-	return c, err
-}
+	c.columns = filter.Columns(c, headers)
+	c.w = c.newWriter(c)
 
-func (c *csvCfg) writeCsvHeaders(headers []string) (err error) {
-	// finally, write the headers if the file is empty
-	s, _ := c.file.Stat()
-	if s.Size() != 0 {
-		return nil
+	// set a default csv path
+	c.path = csvPath
+	if c.partitionLayout != "" {
+		c.partition = time.Now().Format(c.partitionLayout)
+		csvPath = partitionPath(csvPath, c.partition)
 	}
 
-	preHeaders := []string{}
-	if c.IsTimestampFormatterEnabled() {
-		preHeaders = append(preHeaders, "Timestamp")
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err = c.openLocked(csvPath); err != nil {
+		return nil, err
 	}
-	if c.IsLevelFormatterEnabled() {
-		preHeaders = append(preHeaders, "Level")
+
+	return c, nil
+}
+
+// validDelimiter mirrors the delimiter rules of encoding/csv
+func validDelimiter(r rune) bool {
+	return r != 0 && r != '"' && r != '\r' && r != '\n' && utf8.ValidRune(r) && r != utf8.RuneError
+}
+
+// newWriter returns a csv writer with the configured delimiter and line endings
+func (c *csvCfg) newWriter(w io.Writer) *csv.Writer {
+	cw := csv.NewWriter(w)
+	cw.Comma = c.comma
+	cw.UseCRLF = c.crlf
+	return cw
+}
+
+// writeBOM writes the byte order mark if enabled, w must be at the start of the file
+func (c *csvCfg) writeBOM(w io.Writer) error {
+	if !c.bom {
+		return nil
 	}
-	if c.IsCallerFormatterEnabled() {
-		preHeaders = append(preHeaders, "Caller")
+	_, err := io.WriteString(w, bom)
+	return err
+}
+
+// openLocked sets the csv file, writing the header if it's empty or counting its rows otherwise
+func (c *csvCfg) openLocked(path string) error {
+	f, err := c.getCsvFile(path, c.columns, c.comma)
+	if err != nil {
+		return err
 	}
+	c.file = f
 
-	// append message headers
-	preHeaders = append(preHeaders, headers...)
+	s, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	c.size, c.rows = s.Size(), 0
 
-	// finally append error headers
-	// since error are appended at the end by console writer
-	if c.IsErrorFormatterEnabled() {
-		preHeaders = append(preHeaders, "Error")
+	// finally, write the headers if the file is empty
+	if c.size == 0 {
+		if err := c.writeBOM(c); err != nil {
+			return err
+		}
+		return c.writeRowLocked(c.columns)
 	}
-	h := strings.Join(preHeaders, c.GetDelimiter()+" ") + "\n"
 
-	_, err = c.file.WriteString(h)
+	if c.maxRows > 0 {
+		c.rows, err = countRows(f.Name(), c.comma)
+	}
 	return err
 }
 
 // create a csv file writer for the hook
 // remember to call: file.Close()
-func (c *csvCfg) getCsvFile(path string, headers []string) (*os.File, error) {
-	opts := []fo.OpenOption{
-		// create a new file with incremental _number suffix
-		fo.WithIncrementalSuffixIfExists(func(path string, fi fs.FileInfo) bool {
-			// false: don't increment the file name
-			// true: increment the file name
-
-			// if the file doesn't exists
-			if fi == nil {
-				return false
-			}
-
-			b, err := file.ContainsAllTexts(path, 1, 1, headers...)
-			if err != nil {
-				return false
-			}
+func (c *csvCfg) getCsvFile(path string, columns []string, comma rune) (*os.File, error) {
+	opts := []fo.OpenOption{}
 
-			// if the headers are not found and the truncate option is set
-			if !b && c.truncateOnHeadersMissing {
-				return false
+	// nothing to check on a new or empty file
+	if fi := fo.FileStatIfExists(path); fi != nil && fi.Size() > 0 && !hasHeader(path, columns, comma) {
+		switch {
+		case hasLegacyHeader(path, columns, comma):
+			// the same columns written by the former versions, rewritten in place
+			if err := c.migrate(path, columns); err != nil {
+				return nil, err
 			}
-
-			// if the file is empty
-			if fi.Size() == 0 {
-				return false
+		case c.truncateOnHeadersMissing:
+			opts = append(opts, fo.WithTruncate())
+		case c.migrateOnHeadersMismatch:
+			if err := c.migrate(path, columns); err != nil {
+				return nil, err
 			}
-
-			return !b
-		}),
-	}
-	if c.truncateOnHeadersMissing {
-		opts = append(opts, fo.WithTruncate())
+		default:
+			// create a new file with incremental _number suffix
+			opts = append(opts, fo.WithIncrementalSuffixIfExists(func(path string, fi fs.FileInfo) bool {
+				// false: don't increment the file name
+				// true: increment the file name
+				return fi != nil && fi.Size() > 0 && !hasHeader(path, columns, comma)
+			}))
+		}
 	}
 
 	// open the file
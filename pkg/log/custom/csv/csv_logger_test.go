@@ -1,7 +1,13 @@
 package gcsvlog_test
 
 import (
+	"compress/gzip"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	fu "github.com/omgolab/go-commons/pkg/file"
 	glog "github.com/omgolab/go-commons/pkg/log"
@@ -91,3 +97,415 @@ func TestNewCsvLogger(t *testing.T) {
 		})
 	}
 }
+
+func TestCsvLogger_RFC4180(t *testing.T) {
+	path := t.TempDir() + "/report.csv"
+	l, err := lu.New(path, []string{"Name", "Note"}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l.Csv("a,b", "say \"hi\"\nbye")
+	l.CsvErr(errors.New("failed, again"), "c")
+	l.Csv("too", "many", "values")
+
+	recs, err := lu.ReadFile(path, ',')
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) != 3 {
+		t.Fatalf("expected 3 records, got %d: %v", len(recs), recs)
+	}
+
+	want := []map[string]string{
+		{"Level": "DEB", "Name": "a,b", "Note": "say \"hi\"\nbye", "Error": ""},
+		{"Level": "ERR", "Name": "c", "Note": "", "Error": "failed, again"},
+		// the extra values are kept in the last column
+		{"Level": "DEB", "Name": "too", "Note": "many,values", "Error": ""},
+	}
+	for i, w := range want {
+		for k, v := range w {
+			if recs[i][k] != v {
+				t.Errorf("record %d: expected %s=%q, got %q", i, k, v, recs[i][k])
+			}
+		}
+		if recs[i]["Timestamp"] == "" {
+			t.Errorf("record %d: missing timestamp", i)
+		}
+	}
+}
+
+func TestCsvLogger_Structured(t *testing.T) {
+	type Audit struct {
+		By string
+	}
+	type base struct {
+		ID     string
+		secret string
+	}
+	type row struct {
+		Audit
+		base
+		Name    string     `csv:"Name"`
+		Score   float64    `csv:"Score"`
+		At      time.Time  `csv:"At"`
+		Deleted *time.Time `csv:"Deleted"`
+		Total   *big.Int   `csv:"Total"`
+		Note    string     `csv:"-"`
+		hidden  int
+	}
+
+	path := t.TempDir() + "/report.csv"
+	l, err := lu.New(path, []string{"Name", "Score", "At", "Deleted", "Total", "By", "ID"},
+		[]lu.CsvOption{lu.WithTimeFormat(time.DateOnly), lu.WithFloatFormat('f', 2), lu.WithNilValue("null")}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	at := time.Date(2026, 10, 17, 8, 0, 0, 0, time.UTC)
+	row1 := &row{Audit: Audit{By: "x"}, base: base{ID: "7", secret: "s"}, Name: "a", Score: 1.5, At: at, Total: big.NewInt(42), Note: "skipped", hidden: 1}
+	if err := l.CsvStruct(row1); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.CsvMap(map[string]any{"Score": float32(2), "Name": "b", "Deleted": &at}); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.CsvMap(map[string]any{"Unknown": 1}); err == nil {
+		t.Error("expected an unknown column error")
+	}
+	if err := l.CsvStruct(struct{ Other string }{}); err == nil {
+		t.Error("expected an unknown column error")
+	}
+
+	recs, err := lu.ReadFile(path, ',')
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) != 2 {
+		t.Fatalf("expected 2 records, got %d: %v", len(recs), recs)
+	}
+
+	want := []map[string]string{
+		{"Name": "a", "Score": "1.50", "At": "2026-10-17", "Deleted": "null", "Total": "42", "By": "x", "ID": "7"},
+		{"Name": "b", "Score": "2.00", "At": "", "Deleted": "2026-10-17", "Total": "", "By": ""},
+	}
+	for i, w := range want {
+		for k, v := range w {
+			if recs[i][k] != v {
+				t.Errorf("record %d: expected %s=%q, got %q", i, k, v, recs[i][k])
+			}
+		}
+	}
+}
+
+func TestCsvLogger_Migrate(t *testing.T) {
+	path := t.TempDir() + "/report.csv"
+	// the legacy rows have bare quotes, missing values and unquoted delimiters
+	old := "Timestamp, Level, Caller, Name, Gone, Error\n" +
+		"2026-10-17T08:00:00Z, DEB, main.go:12 >, a, x, \n" +
+		"2026-10-17T08:00:01Z, DEB, , say \"hi\", x\n" +
+		"2026-10-17T08:00:02Z, ERR, , c, x, disk, full\n"
+	if err := os.WriteFile(path, []byte(old), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// an earlier backup is kept
+	if err := os.WriteFile(path+lu.BackupSuffix, []byte("earlier"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := lu.New(path, []string{"Name", "Added"}, []lu.CsvOption{lu.WithMigrateOnHeadersMismatch()}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.FileName() != path {
+		t.Errorf("expected the migrated file %s, got %s", path, l.FileName())
+	}
+	l.Csv("b", "new")
+
+	recs, err := lu.ReadFile(path, ',')
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) != 4 {
+		t.Fatalf("expected 4 records, got %d: %v", len(recs), recs)
+	}
+	if recs[0]["Name"] != "a" || recs[0]["Added"] != "" || recs[0]["Timestamp"] != "2026-10-17T08:00:00Z" {
+		t.Errorf("unexpected migrated record: %v", recs[0])
+	}
+	if recs[0]["Caller"] != "main.go:12" {
+		t.Errorf("expected the legacy caller suffix to be stripped: %q", recs[0]["Caller"])
+	}
+	if _, ok := recs[0]["Gone"]; ok {
+		t.Errorf("expected the dropped column to be removed: %v", recs[0])
+	}
+	if recs[1]["Name"] != `say "hi"` || recs[1]["Error"] != "" {
+		t.Errorf("unexpected lenient record: %v", recs[1])
+	}
+	if recs[2]["Error"] != "disk,full" {
+		t.Errorf("expected the extra values in the last column: %v", recs[2])
+	}
+	if recs[3]["Name"] != "b" || recs[3]["Added"] != "new" {
+		t.Errorf("unexpected record: %v", recs[3])
+	}
+
+	earlier, err := os.ReadFile(path + lu.BackupSuffix)
+	if err != nil || string(earlier) != "earlier" {
+		t.Errorf("expected the earlier backup to be kept, got %q (%v)", earlier, err)
+	}
+	backup, err := os.ReadFile(path + ".1" + lu.BackupSuffix)
+	if err != nil || string(backup) != old {
+		t.Errorf("expected the original file as backup, got %q (%v)", backup, err)
+	}
+}
+
+func TestCsvLogger_LegacyHeader(t *testing.T) {
+	path := t.TempDir() + "/report.csv"
+	old := "Timestamp, Level, Caller, Name, Error\n" +
+		"2026-10-17 08:00:00, DEB, , a, \n"
+	if err := os.WriteFile(path, []byte(old), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// the files of the former versions are migrated rather than left for a suffixed file
+	l, err := lu.New(path, []string{"Name"}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.FileName() != path {
+		t.Errorf("expected the migrated file %s, got %s", path, l.FileName())
+	}
+	l.Csv("b")
+
+	recs, err := lu.ReadFile(path, ',')
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) != 2 || recs[0]["Name"] != "a" || recs[0]["Level"] != "DEB" || recs[1]["Name"] != "b" {
+		t.Errorf("unexpected records: %v", recs)
+	}
+}
+
+func TestCsvLogger_ExactHeader(t *testing.T) {
+	path := t.TempDir() + "/report.csv"
+	// the header contains the new column names but not exactly
+	if err := os.WriteFile(path, []byte("Timestamp,Level,Caller,Name,Note,Error,Extra\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := lu.New(path, []string{"Name", "Note"}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.FileName() == path {
+		t.Errorf("expected a new suffixed file, got %s", l.FileName())
+	}
+}
+
+func TestCsvLogger_Rotation(t *testing.T) {
+	dir := t.TempDir()
+	l, err := lu.New(dir+"/report.csv", []string{"N"}, []lu.CsvOption{lu.WithMaxRows(2), lu.WithGzipRotated()}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, n := range []string{"1", "2", "3", "4", "5"} {
+		l.Csv(n)
+	}
+	// the rotated files are compressed in the background
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"report.1.csv.gz", "report.2.csv.gz"} {
+		f, err := os.Open(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		zr, err := gzip.NewReader(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, err := lu.NewReader(zr, ',')
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 2; i++ {
+			if _, err := r.Read(); err != nil {
+				t.Errorf("%s: expected 2 rows: %v", name, err)
+			}
+		}
+		f.Close()
+	}
+
+	recs, err := lu.ReadFile(dir+"/report.csv", ',')
+	if err != nil || len(recs) != 1 || recs[0]["N"] != "5" {
+		t.Errorf("expected the last row in the active file, got %v (%v)", recs, err)
+	}
+}
+
+func TestCsvLogger_RotationFailure(t *testing.T) {
+	dir := t.TempDir()
+	path := dir + "/report.csv"
+	l, err := lu.New(path, []string{"N"}, []lu.CsvOption{lu.WithMaxRows(1)}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Csv("1")
+
+	// the full file can't be renamed anymore, a file is reopened anyway
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	l.Csv("2")
+	l.Csv("3")
+
+	// the row written after the failed rotation was rotated by the next one
+	recs, err := lu.ReadFile(dir+"/report.1.csv", ',')
+	if err != nil || len(recs) != 1 || recs[0]["N"] != "2" {
+		t.Errorf("expected the row written after the failed rotation, got %v (%v)", recs, err)
+	}
+	if recs, err := lu.ReadFile(path, ','); err != nil || len(recs) != 1 || recs[0]["N"] != "3" {
+		t.Errorf("expected the last row in the active file, got %v (%v)", recs, err)
+	}
+	if err := l.Close(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCsvLogger_SizeRotation(t *testing.T) {
+	dir := t.TempDir()
+	l, err := lu.New(dir+"/report.csv", []string{"N"}, []lu.CsvOption{lu.WithMaxFileSize(1)}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Csv("1")
+	l.Csv("2")
+
+	// the header alone reaches the size, each row goes to a new file
+	for _, name := range []string{"report.1.csv", "report.2.csv", "report.csv"} {
+		if _, err := lu.ReadFile(filepath.Join(dir, name), ','); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+}
+
+func TestCsvLogger_TimePartition(t *testing.T) {
+	if _, err := lu.New(t.TempDir()+"/report.csv", nil, []lu.CsvOption{lu.WithTimePartition("daily")}, nil); err == nil {
+		t.Error("expected an invalid layout error")
+	}
+
+	dir := t.TempDir()
+	l, err := lu.New(dir+"/report.csv", []string{"N"}, []lu.CsvOption{lu.WithTimePartition("2006-01-02")}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Csv("1")
+
+	want := filepath.Join(dir, "report_"+time.Now().Format("2006-01-02")+".csv")
+	if l.FileName() != want {
+		t.Errorf("expected %s, got %s", want, l.FileName())
+	}
+
+	// switch partitions every second
+	l, err = lu.New(dir+"/sec.csv", []string{"N"}, []lu.CsvOption{lu.WithTimePartition("150405"), lu.WithGzipRotated()}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := l.FileName()
+	l.Csv("1")
+	time.Sleep(1100 * time.Millisecond)
+	l.Csv("2")
+
+	if l.FileName() == first {
+		t.Errorf("expected a new partition file")
+	}
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(first + lu.GzipSuffix); err != nil {
+		t.Errorf("expected the closed partition to be compressed: %v", err)
+	}
+	if recs, err := lu.ReadFile(l.FileName(), ','); err != nil || len(recs) != 1 || recs[0]["N"] != "2" {
+		t.Errorf("expected the second row in the new partition, got %v (%v)", recs, err)
+	}
+}
+
+func TestCsvLogger_Excel(t *testing.T) {
+	dir := t.TempDir()
+	path := dir + "/report.csv"
+	opts := []lu.CsvOption{lu.WithExcel(), lu.WithComma(';')}
+	l, err := lu.New(path, []string{"Formula", "Amount"}, opts, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Csv("=SUM(A1:A2)", "-5")
+	l.Csv("@cmd", "1,5")
+
+	// the header is still recognized behind the byte order mark
+	l, err = lu.New(path, []string{"Formula", "Amount"}, opts, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Csv("plain", "+1")
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "\ufeffTimestamp;Level;Caller;Formula;Amount;Error\r\n"; len(b) < len(want) || string(b[:len(want)]) != want {
+		t.Errorf("expected the file to start with %q, got %q", want, b)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("expected a single file, got %d", len(entries))
+	}
+
+	recs, err := lu.ReadFile(path, ';')
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) != 3 {
+		t.Fatalf("expected 3 records, got %d: %v", len(recs), recs)
+	}
+
+	want := []map[string]string{
+		{"Formula": "'=SUM(A1:A2)", "Amount": "'-5"},
+		{"Formula": "'@cmd", "Amount": "1,5"},
+		{"Formula": "plain", "Amount": "'+1"},
+	}
+	for i, w := range want {
+		for k, v := range w {
+			if recs[i][k] != v {
+				t.Errorf("record %d: expected %s=%q, got %q", i, k, v, recs[i][k])
+			}
+		}
+		if _, err := time.Parse(time.RFC3339, recs[i]["Timestamp"]); err != nil {
+			t.Errorf("record %d: expected an ISO 8601 timestamp: %v", i, err)
+		}
+	}
+
+	if _, err := lu.New(path, nil, []lu.CsvOption{lu.WithComma('\n')}, nil); err == nil {
+		t.Error("expected an invalid delimiter error")
+	}
+}
+
+func TestCsvLogger_Dedup(t *testing.T) {
+	// the different rows must not be collapsed
+	path := t.TempDir() + "/report.csv"
+	l, err := lu.New(path, []string{"Id", "Name"}, nil, nil, glog.WithDedup(time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Csv("1", "x")
+	l.Csv("2", "y")
+	l.Csv("2", "y")
+	if err := l.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	recs, err := lu.ReadFile(path, ',')
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) != 3 || recs[0]["Id"] != "1" || recs[1]["Id"] != "2" || recs[2]["Id"] != "2" {
+		t.Fatalf("expected the rows 1, 2 and the flushed repeat of 2, got %v", recs)
+	}
+}
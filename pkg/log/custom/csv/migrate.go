@@ -0,0 +1,162 @@
+package gcsvlog
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/exp/slices"
+)
+
+// BackupSuffix is appended to the path of a migrated file to keep the original,
+// numbered if an earlier backup exists: report.csv.bak, report.csv.1.bak, ...
+const BackupSuffix = ".bak"
+
+// legacyCallerSuffix ends the caller values written by the former versions
+const legacyCallerSuffix = " >"
+
+// readHeader parses the first row of a csv file
+func readHeader(path string, comma rune) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(skipBOM(f))
+	r.Comma = comma
+	return r.Read()
+}
+
+// hasHeader reports whether the file header is exactly the given columns
+func hasHeader(path string, columns []string, comma rune) bool {
+	header, err := readHeader(path, comma)
+	return err == nil && slices.Equal(header, columns)
+}
+
+// hasLegacyHeader reports whether the file has the given columns in the header
+// written before the rows were quoted, i.e. joined with the delimiter and a space
+func hasLegacyHeader(path string, columns []string, comma rune) bool {
+	header, err := readHeader(path, comma)
+	if err != nil || !isLegacyHeader(header) || len(header) != len(columns) {
+		return false
+	}
+	for i, h := range header {
+		if strings.TrimLeft(h, " ") != columns[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// migrate rewrites the file with the new columns, keeping the values of the
+// columns with the same name. The original file is renamed with BackupSuffix.
+func (c *csvCfg) migrate(path string, columns []string) error {
+	tmp := path + ".tmp"
+	if err := c.writeMigrated(path, tmp, columns); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("gcsvlog: migrate %s: %w", path, err)
+	}
+
+	backup, err := backupPath(path)
+	if err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(path, backup); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("gcsvlog: backup %s: %w", path, err)
+	}
+	return os.Rename(tmp, path)
+}
+
+func (c *csvCfg) writeMigrated(src, dst string, columns []string) error {
+	header, err := readHeader(src, c.comma)
+	if err != nil {
+		return err
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	// the files written before the rows were quoted have a space after the delimiter,
+	// unescaped quotes and delimiters in the values
+	legacy := isLegacyHeader(header)
+	r, err := newReader(in, c.comma, legacy)
+	if err != nil {
+		return err
+	}
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	if err := c.writeBOM(out); err != nil {
+		return err
+	}
+	w := c.newWriter(out)
+	if err := w.Write(columns); err != nil {
+		return err
+	}
+
+	row := make([]string, len(columns))
+	for {
+		rec, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		for i, name := range columns {
+			row[i] = rec[name]
+		}
+		if legacy {
+			if i := slices.Index(columns, "Caller"); i >= 0 {
+				row[i] = strings.TrimSuffix(row[i], legacyCallerSuffix)
+			}
+		}
+		if err := w.Write(row); err != nil {
+			return err
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+	return out.Sync()
+}
+
+// backupPath returns the first free backup name of path
+func backupPath(path string) (string, error) {
+	if !exists(path + BackupSuffix) {
+		return path + BackupSuffix, nil
+	}
+	for n := 1; n < 1_000_000; n++ {
+		p := fmt.Sprintf("%s.%d%s", path, n, BackupSuffix)
+		if !exists(p) {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("gcsvlog: no free backup name for %s", path)
+}
+
+// isLegacyHeader reports whether all the header columns but the first start with a space
+func isLegacyHeader(header []string) bool {
+	for _, h := range header[1:] {
+		if len(h) == 0 || h[0] != ' ' {
+			return false
+		}
+	}
+	return len(header) > 1
+}
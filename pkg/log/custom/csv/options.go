@@ -1,5 +1,12 @@
 package gcsvlog
 
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
 type CsvOption func(*csvCfg) error
 
 func WithTruncateOnHeadersMissing() CsvOption {
@@ -8,3 +15,149 @@ func WithTruncateOnHeadersMissing() CsvOption {
 		return nil
 	}
 }
+
+// WithMigrateOnHeadersMismatch rewrites an existing file having another header:
+// the old columns are mapped by name, the new ones are left empty and dropped
+// columns are lost. The original file is kept as <path>.bak (or the next free <path>.N.bak)
+func WithMigrateOnHeadersMismatch() CsvOption {
+	return func(ch *csvCfg) error {
+		ch.migrateOnHeadersMismatch = true
+		return nil
+	}
+}
+
+// WithTimeFormat sets the layout of the time values written by CsvStruct and CsvMap (default: time.RFC3339)
+func WithTimeFormat(layout string) CsvOption {
+	return func(ch *csvCfg) error {
+		ch.timeFormat = layout
+		return nil
+	}
+}
+
+// WithFloatFormat sets the strconv.FormatFloat format and precision of the float values (default: 'g', -1)
+func WithFloatFormat(format byte, precision int) CsvOption {
+	return func(ch *csvCfg) error {
+		switch format {
+		case 'b', 'e', 'E', 'f', 'g', 'G', 'x', 'X':
+		default:
+			return fmt.Errorf("gcsvlog: invalid float format %q", format)
+		}
+		ch.floatFormat = format
+		ch.floatPrecision = precision
+		return nil
+	}
+}
+
+// WithNilValue sets the text written for the nil values (default: empty)
+func WithNilValue(s string) CsvOption {
+	return func(ch *csvCfg) error {
+		ch.nilValue = s
+		return nil
+	}
+}
+
+// WithMaxFileSize starts a new file once the current one reaches maxBytes,
+// the full file is renamed with a number (report.csv -> report.1.csv)
+func WithMaxFileSize(maxBytes int64) CsvOption {
+	return func(ch *csvCfg) error {
+		if maxBytes <= 0 {
+			return errors.New("gcsvlog: the max file size must be positive")
+		}
+		ch.maxSize = maxBytes
+		return nil
+	}
+}
+
+// WithMaxRows starts a new file once the current one has maxRows rows,
+// the full file is renamed with a number (report.csv -> report.1.csv)
+func WithMaxRows(maxRows int) CsvOption {
+	return func(ch *csvCfg) error {
+		if maxRows <= 0 {
+			return errors.New("gcsvlog: the max rows must be positive")
+		}
+		ch.maxRows = maxRows
+		return nil
+	}
+}
+
+// WithTimePartition writes to a file per time partition named by formatting
+// the current time with layout, e.g. "2006-01-02" gives report_2026-10-17.csv
+func WithTimePartition(layout string) CsvOption {
+	return func(ch *csvCfg) error {
+		if p := time.Now().Format(layout); layout == "" || p == layout || strings.ContainsAny(p, `/\`) {
+			return fmt.Errorf("gcsvlog: invalid time partition layout %q", layout)
+		}
+		ch.partitionLayout = layout
+		return nil
+	}
+}
+
+// WithGzipRotated compresses the closed files (rotated or of a past partition) to <name>.gz
+func WithGzipRotated() CsvOption {
+	return func(ch *csvCfg) error {
+		ch.gzipRotated = true
+		return nil
+	}
+}
+
+// WithComma sets the csv delimiter instead of the filter delimiter,
+// e.g. ';' for the spreadsheet tools of the locales using a decimal comma
+func WithComma(comma rune) CsvOption {
+	return func(ch *csvCfg) error {
+		if !validDelimiter(comma) {
+			return fmt.Errorf("gcsvlog: invalid delimiter %q", comma)
+		}
+		ch.comma = comma
+		return nil
+	}
+}
+
+// WithBOM writes the UTF-8 byte order mark at the start of the new files,
+// it makes spreadsheet tools detect the encoding. The readers skip it.
+func WithBOM() CsvOption {
+	return func(ch *csvCfg) error {
+		ch.bom = true
+		return nil
+	}
+}
+
+// WithCRLF ends the rows with \r\n instead of \n
+func WithCRLF() CsvOption {
+	return func(ch *csvCfg) error {
+		ch.crlf = true
+		return nil
+	}
+}
+
+// WithFormulaEscape prefixes the row values starting with '=', '+', '-', '@', a tab
+// or a carriage return with a single quote, so spreadsheet tools don't evaluate them
+// as formulas. Note: negative numbers are escaped too.
+func WithFormulaEscape() CsvOption {
+	return func(ch *csvCfg) error {
+		ch.formulaEscape = true
+		return nil
+	}
+}
+
+// WithISO8601Timestamps writes the Timestamp column and the time values in
+// ISO 8601 (time.RFC3339) instead of the filter timestamp format
+func WithISO8601Timestamps() CsvOption {
+	return func(ch *csvCfg) error {
+		ch.isoTimestamps = true
+		ch.timeFormat = time.RFC3339
+		return nil
+	}
+}
+
+// WithExcel combines the options for the spreadsheet tools: WithBOM, WithCRLF,
+// WithFormulaEscape and WithISO8601Timestamps. See WithComma for the locales expecting ';'
+func WithExcel() CsvOption {
+	return func(ch *csvCfg) error {
+		for _, opt := range []CsvOption{WithBOM(), WithCRLF(), WithFormulaEscape(), WithISO8601Timestamps()} {
+			if err := opt(ch); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
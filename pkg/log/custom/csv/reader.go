@@ -0,0 +1,110 @@
+package gcsvlog
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"io"
+	"os"
+	"strings"
+)
+
+// Record is a csv log row keyed by the column names of the file header
+type Record map[string]string
+
+// Reader parses the files written by the csv logger
+type Reader struct {
+	r      *csv.Reader
+	header []string
+	comma  string
+}
+
+// NewReader reads the header from r, the rows must have the same column count
+func NewReader(r io.Reader, delimiter rune) (*Reader, error) {
+	return newReader(r, delimiter, false)
+}
+
+// newReader reads the legacy files leniently: leading spaces are trimmed, bare quotes are kept,
+// the missing values are left empty and the extra ones are joined into the last column
+func newReader(r io.Reader, delimiter rune, legacy bool) (*Reader, error) {
+	cr := csv.NewReader(skipBOM(r))
+	cr.Comma = delimiter
+	cr.TrimLeadingSpace = legacy
+	cr.LazyQuotes = legacy
+
+	header, err := cr.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("gcsvlog: missing csv header")
+		}
+		return nil, err
+	}
+	cr.FieldsPerRecord = len(header)
+	if legacy {
+		cr.FieldsPerRecord = -1
+	}
+
+	return &Reader{r: cr, header: header, comma: string(delimiter)}, nil
+}
+
+// bom is the UTF-8 byte order mark written by WithBOM
+const bom = "\ufeff"
+
+// skipBOM returns a reader skipping the byte order mark starting r
+func skipBOM(r io.Reader) io.Reader {
+	br := bufio.NewReader(r)
+	if b, err := br.Peek(len(bom)); err == nil && string(b) == bom {
+		_, _ = br.Discard(len(bom))
+	}
+	return br
+}
+
+// Header returns the column names
+func (r *Reader) Header() []string {
+	return r.header
+}
+
+// Read returns the next record or io.EOF at the end of the file
+func (r *Reader) Read() (Record, error) {
+	row, err := r.r.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	// only the legacy rows can have more values than the header
+	if n := len(r.header); len(row) > n {
+		row = append(row[:n-1:n-1], strings.Join(row[n-1:], r.comma))
+	}
+
+	rec := make(Record, len(r.header))
+	for i, v := range row {
+		rec[r.header[i]] = v
+	}
+	return rec, nil
+}
+
+// ReadFile parses all the records of a csv log file
+func ReadFile(path string, delimiter rune) ([]Record, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r, err := NewReader(f, delimiter)
+	if err != nil {
+		return nil, err
+	}
+
+	var recs []Record
+	for {
+		rec, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return recs, nil
+		}
+		if err != nil {
+			return recs, err
+		}
+		recs = append(recs, rec)
+	}
+}
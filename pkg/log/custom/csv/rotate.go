@@ -0,0 +1,166 @@
+package gcsvlog
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// GzipSuffix is appended to the closed files compressed by WithGzipRotated
+const GzipSuffix = ".gz"
+
+type rotation struct {
+	maxSize         int64
+	maxRows         int
+	partitionLayout string
+	gzipRotated     bool
+
+	// the path given to New and the active partition
+	path      string
+	partition string
+
+	compressing sync.WaitGroup
+}
+
+// shouldRotateLocked reports whether the next row goes to a new file
+func (c *csvCfg) shouldRotateLocked() bool {
+	if c.partitionLayout != "" && time.Now().Format(c.partitionLayout) != c.partition {
+		return true
+	}
+	return (c.maxSize > 0 && c.size >= c.maxSize) || (c.maxRows > 0 && c.rows >= c.maxRows)
+}
+
+// rotateLocked closes the current file and opens the next one: the file of the new
+// partition, or the same path once the full file is renamed with a number.
+// A file is reopened whatever fails so the logger keeps writing.
+func (c *csvCfg) rotateLocked() error {
+	closed := c.file.Name()
+	path := closed
+	isNewPartition := false
+	if p := time.Now().Format(c.partitionLayout); c.partitionLayout != "" && p != c.partition {
+		c.partition = p
+		path = partitionPath(c.path, p)
+		isNewPartition = true
+	}
+
+	err := c.file.Close()
+	if err == nil && !isNewPartition {
+		var numbered string
+		if numbered, err = numberedPath(closed); err == nil {
+			if err = os.Rename(closed, numbered); err == nil {
+				closed = numbered
+			}
+		}
+	}
+
+	if oerr := c.openLocked(path); oerr != nil {
+		return errors.Join(err, oerr)
+	}
+	if err == nil && c.gzipRotated {
+		c.compress(closed)
+	}
+	return err
+}
+
+// compress gzips the closed file in the background, a failure only leaves it uncompressed
+func (c *csvCfg) compress(path string) {
+	c.compressing.Add(1)
+	go func() {
+		defer c.compressing.Done()
+		if err := gzipFile(path); err != nil {
+			c.Error("gcsvlog: compress rotated file", err)
+		}
+	}()
+}
+
+// partitionPath inserts the partition before the extension: report.csv -> report_2026-10-17.csv
+func partitionPath(path, partition string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "_" + partition + ext
+}
+
+// numberedPath returns the first free rotated name: report.csv -> report.1.csv
+func numberedPath(path string) (string, error) {
+	ext := filepath.Ext(path)
+	base := strings.TrimSuffix(path, ext)
+	for n := 1; n < 1_000_000; n++ {
+		p := fmt.Sprintf("%s.%d%s", base, n, ext)
+		if !exists(p) && !exists(p+GzipSuffix) {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("gcsvlog: no free rotated name for %s", path)
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// gzipFile compresses the file to path.gz and removes it
+func gzipFile(path string) (err error) {
+	in, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(path+GzipSuffix, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := out.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			_ = os.Remove(path + GzipSuffix)
+		}
+	}()
+
+	zw := gzip.NewWriter(out)
+	zw.Name = filepath.Base(path)
+	if _, err = io.Copy(zw, in); err != nil {
+		return err
+	}
+	if err = zw.Close(); err != nil {
+		return err
+	}
+
+	in.Close()
+	return os.Remove(path)
+}
+
+// countRows returns the number of rows after the header
+func countRows(path string, comma rune) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	r, err := NewReader(f, comma)
+	if err != nil {
+		return 0, err
+	}
+	r.r.FieldsPerRecord = -1
+	r.r.ReuseRecord = true
+
+	n := 0
+	for {
+		_, err := r.r.Read()
+		if errors.Is(err, io.EOF) {
+			return n, nil
+		}
+		if err != nil {
+			return n, err
+		}
+		n++
+	}
+}
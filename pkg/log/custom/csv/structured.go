@@ -0,0 +1,188 @@
+package gcsvlog
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+
+	log "github.com/omgolab/go-commons/pkg/log"
+	"golang.org/x/exp/slices"
+)
+
+// CsvStruct writes a row from the exported fields of a struct (or a pointer to one).
+// The column is named by the `csv:"name"` tag or by the field name, "-" skips the field.
+// The fields of the embedded structs without a tag are flattened, unexported ones included.
+func (c *csvCfg) CsvStruct(v any) error {
+	values, err := c.structValues(v)
+	if err != nil {
+		return err
+	}
+	c.csv(log.DebugLevel, nil, values)
+	return nil
+}
+
+// CsvMap writes a row placing the values under the matching headers
+func (c *csvCfg) CsvMap(m map[string]any) error {
+	values, err := c.mapValues(m)
+	if err != nil {
+		return err
+	}
+	c.csv(log.DebugLevel, nil, values)
+	return nil
+}
+
+func (c *csvCfg) structValues(v any) ([]string, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil, errors.New("gcsvlog: nil struct")
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("gcsvlog: expected a struct, got %T", v)
+	}
+
+	values := make([]string, len(c.headers))
+	if err := c.setStructValues(values, rv); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// setStructValues sets the values of the struct fields, the embedded structs are flattened
+func (c *csvCfg) setStructValues(values []string, rv reflect.Value) error {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		tag, hasTag := sf.Tag.Lookup("csv")
+		if tag == "-" {
+			continue
+		}
+
+		// like encoding/json, the exported fields of an unexported embedded struct are promoted
+		if !sf.IsExported() && !(sf.Anonymous && isStructType(sf.Type)) {
+			continue
+		}
+
+		name := sf.Name
+		if tag != "" {
+			name = tag
+		}
+
+		fv := rv.Field(i)
+		if sf.Anonymous && (!hasTag || !sf.IsExported()) {
+			if fv.Kind() == reflect.Pointer && fv.Type().Elem().Kind() == reflect.Struct {
+				if fv.IsNil() {
+					continue
+				}
+				fv = fv.Elem()
+			}
+			if fv.Kind() == reflect.Struct {
+				if err := c.setStructValues(values, fv); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+
+		if err := c.setValue(values, name, fv.Interface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *csvCfg) mapValues(m map[string]any) ([]string, error) {
+	values := make([]string, len(c.headers))
+	for name, v := range m {
+		if err := c.setValue(values, name, v); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+func (c *csvCfg) setValue(values []string, name string, v any) error {
+	i := slices.Index(c.headers, name)
+	if i < 0 {
+		return fmt.Errorf("gcsvlog: unknown column %q", name)
+	}
+	values[i] = c.format(v)
+	return nil
+}
+
+// format stringifies a cell value with the configured time, float and nil formats
+func (c *csvCfg) format(v any) string {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return c.nilValue
+		}
+		// the pointer receivers (e.g. *big.Int) are lost once dereferenced,
+		// the value receivers (e.g. time.Time) are formatted below
+		if rv.Kind() == reflect.Pointer && !implementsFormatter(rv.Type().Elem()) {
+			switch x := rv.Interface().(type) {
+			case error:
+				return x.Error()
+			case fmt.Stringer:
+				return x.String()
+			}
+		}
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() {
+		return c.nilValue
+	}
+
+	switch x := rv.Interface().(type) {
+	case string:
+		return x
+	case time.Time:
+		return x.Format(c.timeFormat)
+	case time.Duration:
+		return x.String()
+	case []byte:
+		return string(x)
+	case error:
+		return x.Error()
+	case fmt.Stringer:
+		return x.String()
+	}
+
+	switch rv.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(rv.Float(), c.floatFormat, c.floatPrecision, rv.Type().Bits())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(rv.Uint(), 10)
+	case reflect.Bool:
+		return strconv.FormatBool(rv.Bool())
+	case reflect.Slice, reflect.Map:
+		if rv.IsNil() {
+			return c.nilValue
+		}
+	}
+	return fmt.Sprint(rv.Interface())
+}
+
+var (
+	errorType    = reflect.TypeOf((*error)(nil)).Elem()
+	stringerType = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+)
+
+// implementsFormatter reports whether the values of t are errors or stringers
+func implementsFormatter(t reflect.Type) bool {
+	return t.Implements(errorType) || t.Implements(stringerType)
+}
+
+// isStructType reports whether t is a struct or a pointer to one
+func isStructType(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct
+}
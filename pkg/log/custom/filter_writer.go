@@ -2,15 +2,22 @@ package gcustomlog
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"strconv"
+	"strings"
 	"time"
 
 	glog "github.com/omgolab/go-commons/pkg/log"
 	"github.com/rs/zerolog"
+	"golang.org/x/exp/slices"
 )
 
+// TagFieldName is the field routing the events logged by TagLog to the filter writer
+const TagFieldName = glog.FilterTagFieldName
+
 type FilterLogger interface {
 	glog.Logger
 	TagLog(msg string, level glog.LogLevel, err error, csfCount int, fields ...glog.LogFields)
@@ -26,9 +33,13 @@ type FilterLogger interface {
 type filterWriter struct {
 	glog.Logger
 	tag                    string
+	tagField               []byte
 	delimiter              string
 	writer                 io.Writer
+	console                zerolog.ConsoleWriter
+	recordLines            bool
 	partsOrder             []string
+	extraColumns           []string
 	timestampFormatter     zerolog.Formatter
 	levelFormatter         zerolog.Formatter
 	messageFormatter       zerolog.Formatter
@@ -64,52 +75,171 @@ func (fw *filterWriter) IsErrorFormatterEnabled() bool {
 }
 
 func (fw *filterWriter) TagLog(msg string, level glog.LogLevel, err error, csfCount int, fields ...glog.LogFields) {
-	msg += fw.delimiter + " " + fw.tag
+	// the tag goes last so it can't be overridden, the caller's slice is left untouched
+	fields = append(fields[:len(fields):len(fields)], glog.LogFields{TagFieldName: fw.tag})
 	fw.Event(msg, level, err, csfCount, fields...)
 }
 
-// Write implements io.Writer and only writes to underlying writer if the tag exists
+// Write implements io.Writer and only writes the tagged events to the console writer
 func (fw *filterWriter) Write(b []byte) (n int, err error) {
-	ln := len(b) // used for returning the original length
-	bi := []byte(fw.tag)
-	l := len(bi) + 1 // 1 for the delimiter or a ending newline
+	n = len(b) // used for returning the original length
+	if !fw.isTagged(b) {
+		return n, nil
+	}
+	var evt struct {
+		Tag     string  `json:"filter-tag"`
+		Message *string `json:"message"`
+	}
+	if json.Unmarshal(b, &evt) != nil || evt.Tag != fw.tag {
+		return n, nil
+	}
 
-	for {
-		m := bytes.LastIndex(b, bi)
-		if m < 0 {
-			break
-		}
+	return n, fw.renderLine(b, evt.Message != nil, fw.writer)
+}
 
-		j := m + l
-		if j < len(b) && b[j] == ' ' {
-			j++
-		} else if j == len(b) {
-			j--
+// isTagged is a cheap check run before decoding the event, the tag is matched in its json encoding
+func (fw *filterWriter) isTagged(b []byte) bool {
+	return bytes.Contains(b, fw.tagField)
+}
+
+// tagField returns the json field of the tag as written by zerolog
+func tagField(tag string) []byte {
+	buf := &bytes.Buffer{}
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(tag)
+	return append([]byte(`"`+TagFieldName+`":`), bytes.TrimSuffix(buf.Bytes(), []byte("\n"))...)
+}
+
+// extraColumnMarker prefixes the extra column values rendered before the console writer
+const extraColumnMarker = "\x00gcustomlog-extra:"
+
+// renderLine writes the console line of a json event to w
+func (fw *filterWriter) renderLine(b []byte, hasMessage bool, w io.Writer) error {
+	cw := fw.console
+	cw.Out = &lineWriter{delimiter: fw.delimiter, w: w}
+
+	if len(fw.extraColumns) > 0 {
+		var err error
+		if b, err = fw.renderExtraColumns(b); err != nil {
+			return err
+		}
+		// the rendered extra columns are passed through, the other values are formatted as usual
+		cw.FormatFieldValue = func(i interface{}) string {
+			if s, ok := i.(string); ok && strings.HasPrefix(s, extraColumnMarker) {
+				return strings.TrimPrefix(s, extraColumnMarker)
+			}
+			return fw.fieldValueFormatter(i)
 		}
+	} else if !hasMessage && len(b) > 0 && b[0] == '{' {
+		// zerolog omits an empty message, keep its part (and delimiter) in the line
+		b = append([]byte(`{"`+zerolog.MessageFieldName+`":"",`), b[1:]...)
+	}
+
+	_, err := cw.Write(b)
+	return err
+}
 
-		copy(b[m:], b[j:])
-		b = b[:len(b)-(j-m)]
+// renderExtraColumns replaces the extra column values of the event with their marked rendering,
+// a missing message is added to keep its part
+func (fw *filterWriter) renderExtraColumns(b []byte) ([]byte, error) {
+	var evt map[string]json.RawMessage
+	if err := json.Unmarshal(b, &evt); err != nil {
+		return nil, fmt.Errorf("cannot decode event: %w", err)
 	}
 
-	bl := len(b)
-	if bl < ln {
-		d := 0
-		if bl >= 2 {
-			if b[bl-3] == fw.delimiter[0] {
-				d = 3
-			} else if b[bl-2] == fw.delimiter[0] {
-				d = 2
+	if _, ok := evt[zerolog.MessageFieldName]; !ok {
+		evt[zerolog.MessageFieldName] = json.RawMessage(`""`)
+	}
+	for _, name := range fw.extraColumns {
+		var v any
+		if raw, ok := evt[name]; ok {
+			d := json.NewDecoder(bytes.NewReader(raw))
+			d.UseNumber()
+			if err := d.Decode(&v); err != nil {
+				return nil, fmt.Errorf("cannot decode %s: %w", name, err)
 			}
 		}
-		// remove ending delimiter
-		if d > 0 {
-			b[bl-d] = '\n'
-			b = b[:bl-d+1]
+
+		rendered, err := json.Marshal(extraColumnMarker + fw.formatExtraColumn(v))
+		if err != nil {
+			return nil, err
+		}
+		evt[name] = rendered
+	}
+	return json.Marshal(evt)
+}
+
+// formatExtraColumn formats an extra column value, a missing value keeps an empty column
+func (fw *filterWriter) formatExtraColumn(i interface{}) string {
+	if i == nil {
+		return fw.delimiter
+	}
+	return fmt.Sprint(i) + fw.delimiter
+}
+
+func isStandardPart(name string) bool {
+	switch name {
+	case zerolog.TimestampFieldName, zerolog.LevelFieldName, zerolog.CallerFieldName, zerolog.MessageFieldName:
+		return true
+	}
+	return false
+}
+
+// addExtraColumn registers the extra column and appends it to the parts order if missing
+func (fw *filterWriter) addExtraColumn(name string) error {
+	switch {
+	case name == "":
+		return errors.New("gcustomlog: empty extra column name")
+	case isStandardPart(name), name == TagFieldName:
+		return fmt.Errorf("gcustomlog: %q can't be an extra column", name)
+	case slices.Contains(fw.extraColumns, name):
+		return fmt.Errorf("gcustomlog: duplicate extra column %q", name)
+	}
+
+	fw.extraColumns = append(fw.extraColumns, name)
+	if !slices.Contains(fw.partsOrder, name) {
+		fw.partsOrder = append(fw.partsOrder, name)
+	}
+	return nil
+}
+
+// validatePartsOrder checks the parts are listed once and the extra columns are placed
+func (fw *filterWriter) validatePartsOrder() error {
+	seen := make(map[string]bool, len(fw.partsOrder))
+	for _, p := range fw.partsOrder {
+		if seen[p] {
+			return fmt.Errorf("gcustomlog: duplicate part %q", p)
+		}
+		seen[p] = true
+	}
+
+	for _, c := range fw.extraColumns {
+		if !seen[c] {
+			return fmt.Errorf("gcustomlog: extra column %q is missing from the parts order", c)
 		}
+	}
+	return nil
+}
 
-		_, err = fw.writer.Write(b)
+// lineWriter removes the delimiter (and the part separator) ending the formatted line
+type lineWriter struct {
+	delimiter string
+	w         io.Writer
+}
+
+func (lw *lineWriter) Write(b []byte) (int, error) {
+	ln := len(b) // used for returning the original length
+	line := bytes.TrimSuffix(b, []byte("\n"))
+	trimmed := bytes.TrimSuffix(line, []byte(lw.delimiter+" "))
+	if len(trimmed) == len(line) {
+		trimmed = bytes.TrimSuffix(line, []byte(lw.delimiter))
+	}
+	if len(trimmed) < len(line) {
+		b = append(trimmed, '\n')
 	}
 
+	_, err := lw.w.Write(b)
 	return ln, err
 }
 
@@ -117,18 +247,37 @@ func New(tag string, w io.Writer, filterOpts []FilterOption, logOpts ...glog.Log
 	if tag == "" || w == nil {
 		return nil, errors.New("tag or writer is invalid")
 	}
+
+	fw, err := newFilterWriter(tag, filterOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	fw.writer = w
+	logOpts = append(logOpts, glog.WithMultiLogger(fw), glog.WithConsoleFieldsExcluded(TagFieldName, ValuesFieldName))
+
+	fw.Logger, err = glog.New(logOpts...)
+	return fw, err
+}
+
+func defaultPartsOrder() []string {
+	return []string{
+		zerolog.TimestampFieldName,
+		zerolog.LevelFieldName,
+		zerolog.CallerFieldName,
+		zerolog.MessageFieldName,
+	}
+}
+
+// newFilterWriter creates the filter writer with the default formatters and applies the options
+func newFilterWriter(tag string, filterOpts []FilterOption) (*filterWriter, error) {
 	defaultFormatter := func(i interface{}) string { return "" }
 
 	fw := &filterWriter{
-		tag:       fmt.Sprintf("tag=%s-%d", tag, time.Now().UnixNano()), // make the tag unique
-		delimiter: ",",
-		writer:    w,
-		partsOrder: []string{
-			zerolog.TimestampFieldName,
-			zerolog.LevelFieldName,
-			zerolog.CallerFieldName,
-			zerolog.MessageFieldName,
-		},
+		// make the tag unique
+		tag:                 fmt.Sprintf("tag=%s-%s", tag, strconv.FormatInt(time.Now().UnixNano(), 36)),
+		delimiter:           ",",
+		partsOrder:          defaultPartsOrder(),
 		fieldNameFormatter:  defaultFormatter,
 		fieldValueFormatter: defaultFormatter,
 	}
@@ -146,14 +295,18 @@ func New(tag string, w io.Writer, filterOpts []FilterOption, logOpts ...glog.Log
 
 	// apply the options
 	for _, opt := range filterOpts {
-		if err = opt(fw); err != nil {
+		if err := opt(fw); err != nil {
 			return nil, err
 		}
 	}
 
-	// set console writer
-	cw := zerolog.ConsoleWriter{
-		Out:                 fw,
+	if err := fw.validatePartsOrder(); err != nil {
+		return nil, err
+	}
+	fw.tagField = tagField(fw.tag)
+
+	// set console writer, its output is set per line
+	fw.console = zerolog.ConsoleWriter{
 		FormatTimestamp:     fw.timestampFormatter,
 		FormatLevel:         fw.levelFormatter,
 		FormatCaller:        fw.callerFormatter,
@@ -164,9 +317,8 @@ func New(tag string, w io.Writer, filterOpts []FilterOption, logOpts ...glog.Log
 		FormatErrFieldValue: fw.errFieldValueFormatter,
 		NoColor:             true,
 		PartsOrder:          fw.partsOrder,
+		FieldsExclude:       append([]string{glog.ErrorChainFieldName, zerolog.ErrorStackFieldName, TagFieldName, ValuesFieldName}, fw.extraColumns...),
 	}
-	logOpts = append(logOpts, glog.WithMultiLogger(cw))
 
-	fw.Logger, err = glog.New(logOpts...)
-	return fw, err
+	return fw, nil
 }
@@ -0,0 +1,214 @@
+package gcustomlog_test
+
+import (
+	"bytes"
+	"errors"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/tj/assert"
+
+	glog "github.com/omgolab/go-commons/pkg/log"
+	filter "github.com/omgolab/go-commons/pkg/log/custom"
+)
+
+var timestampRe = regexp.MustCompile(`(?m)^\d{4}-\d\d-\d\dT\d\d:\d\d:\d\d(Z|[+-]\d\d:\d\d)`)
+
+// nextLine returns the line following the call, i.e. the caller of the next log call
+func nextLine() int {
+	_, _, line, _ := runtime.Caller(1)
+	return line + 1
+}
+
+func TestFilterWriter_Output(t *testing.T) {
+	upper := func(i interface{}) string {
+		s, _ := i.(string)
+		return "<" + s + ">"
+	}
+
+	// the caller line of the error case, LINE in want
+	var line int
+	testCases := []struct {
+		name string
+		opts []filter.FilterOption
+		log  func(l filter.FilterLogger)
+		want string
+	}{
+		{
+			name: "message",
+			log:  func(l filter.FilterLogger) { l.TagLog("hello", glog.DebugLevel, nil, 2) },
+			want: "TS, DEB, hello\n",
+		},
+		{
+			name: "error and fields",
+			log: func(l filter.FilterLogger) {
+				line = nextLine()
+				l.TagLog("failed", glog.ErrorLevel, errors.New("boom"), 2, glog.LogFields{"id": 1})
+			},
+			want: "TS, ERR, filter_writer_test.go:LINE >, failed, error=boom\n",
+		},
+		{
+			name: "empty message",
+			log:  func(l filter.FilterLogger) { l.TagLog("", glog.InfoLevel, nil, 2) },
+			want: "TS, INF, \n",
+		},
+		{
+			name: "message ending with the delimiter",
+			log:  func(l filter.FilterLogger) { l.TagLog("a,", glog.InfoLevel, nil, 2) },
+			want: "TS, INF, a,\n",
+		},
+		{
+			// the tag used to be cut with the character following it
+			name: "custom message formatter",
+			opts: []filter.FilterOption{filter.WithMessageFormatter(upper)},
+			log:  func(l filter.FilterLogger) { l.TagLog("hello", glog.InfoLevel, nil, 2) },
+			want: "TS, INF, <hello>\n",
+		},
+		{
+			name: "delimiter set after the formatters",
+			opts: []filter.FilterOption{filter.WithFieldValueFormatter(), filter.WithDelimiter(';')},
+			log:  func(l filter.FilterLogger) { l.TagLog("hello", glog.InfoLevel, nil, 2) },
+			want: "TS; INF; hello\n",
+		},
+		{
+			name: "extra column",
+			opts: filter.NewFilterBuilder().ExtraColumns("context-ns").Options(),
+			log: func(l filter.FilterLogger) {
+				l.UpdateBaseLogger(l.SetContextNS("db"))
+				l.TagLog("hello", glog.InfoLevel, nil, 2, glog.LogFields{"id": "1"})
+			},
+			want: "TS, INF, hello, db\n",
+		},
+		{
+			name: "extra columns rendered from the event",
+			opts: filter.NewFilterBuilder().PartsOrder("attempt", "level", "message", "context-ns").ExtraColumns("context-ns", "attempt").Options(),
+			log: func(l filter.FilterLogger) {
+				l.TagLog("", glog.WarnLevel, nil, 2, glog.LogFields{"attempt": 3, "context-ns": "a<b"})
+			},
+			want: "3, WAR, , a<b\n",
+		},
+		{
+			name: "missing extra column",
+			opts: filter.NewFilterBuilder().PartsOrder("time", "context-ns", "message").ExtraColumns("context-ns").Options(),
+			log:  func(l filter.FilterLogger) { l.TagLog("hello", glog.InfoLevel, nil, 2) },
+			want: "TS, , hello\n",
+		},
+		{
+			// the lines with an error used to end with a comma
+			name: "error with another delimiter",
+			opts: []filter.FilterOption{filter.WithDelimiter(';'), filter.WithCallerFormatter(func(i interface{}) string { return "" })},
+			log:  func(l filter.FilterLogger) { l.TagLog("failed", glog.ErrorLevel, errors.New("boom"), 2) },
+			want: "TS; ERR; failed; error=boom,\n",
+		},
+		{
+			name: "untagged events are dropped",
+			log:  func(l filter.FilterLogger) { l.Info("not tagged") },
+			want: "",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			l, err := filter.New("test", buf, tt.opts, glog.WithWriters())
+			assert.NoError(t, err)
+
+			tt.log(l)
+			want := strings.ReplaceAll(tt.want, "LINE", strconv.Itoa(line))
+			assert.Equal(t, want, timestampRe.ReplaceAllString(buf.String(), "TS"))
+		})
+	}
+}
+
+func TestFilterBuilder_Validation(t *testing.T) {
+	testCases := []struct {
+		name    string
+		builder *filter.FilterBuilder
+		wantErr string
+	}{
+		{
+			name:    "valid",
+			builder: filter.NewFilterBuilder().Delimiter(';').PartsOrder("level", "message", "context-ns").ExtraColumns("context-ns"),
+		},
+		{
+			name:    "unknown part",
+			builder: filter.NewFilterBuilder().PartsOrder("time", "lvl", "message"),
+			wantErr: `gcustomlog: unknown part "lvl"`,
+		},
+		{
+			name:    "duplicate part",
+			builder: filter.NewFilterBuilder().PartsOrder("time", "message", "time"),
+			wantErr: `gcustomlog: duplicate part "time"`,
+		},
+		{
+			name:    "conflicting delimiters",
+			builder: filter.NewFilterBuilder().Delimiter(';').Delimiter(','),
+			wantErr: `gcustomlog: conflicting delimiters ';' and ','`,
+		},
+		{
+			name:    "invalid delimiter",
+			builder: filter.NewFilterBuilder().Delimiter('"'),
+			wantErr: `gcustomlog: invalid delimiter '"'`,
+		},
+		{
+			name:    "extra column missing from the order",
+			builder: filter.NewFilterBuilder().PartsOrder("time", "message").ExtraColumns("context-ns"),
+			wantErr: `gcustomlog: extra column "context-ns" is missing from the parts order`,
+		},
+		{
+			name:    "standard extra column",
+			builder: filter.NewFilterBuilder().ExtraColumns("message"),
+			wantErr: `gcustomlog: "message" can't be an extra column`,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.builder.Build()
+			_, newErr := filter.New("test", &bytes.Buffer{}, tt.builder.Options(), glog.WithWriters())
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				assert.NoError(t, newErr)
+				return
+			}
+			assert.EqualError(t, err, tt.wantErr)
+			assert.EqualError(t, newErr, tt.wantErr)
+		})
+	}
+}
+
+func TestWithPartsOrder(t *testing.T) {
+	// the missing parts are appended once, another name renders its field;
+	// the empty trailing column keeps its separator like an empty message
+	buf := &bytes.Buffer{}
+	l, err := filter.New("test", buf, []filter.FilterOption{
+		filter.WithExtraColumns("context-ns"),
+		filter.WithPartsOrder([]string{"message", "context-ns", "level"}),
+	}, glog.WithWriters())
+	assert.NoError(t, err)
+	l.TagLog("hello", glog.InfoLevel, nil, 2)
+	assert.Equal(t, "TS, INF, hello, \n", timestampRe.ReplaceAllString(buf.String(), "TS"))
+
+	buf.Reset()
+	l, err = filter.New("test", buf, []filter.FilterOption{filter.WithFieldValueFormatter(), filter.WithPartsOrder([]string{"ns"})}, glog.WithWriters())
+	assert.NoError(t, err)
+	l.TagLog("hello", glog.InfoLevel, nil, 2, glog.LogFields{"ns": "api"})
+	// zerolog renders the part and still lists the field
+	assert.Equal(t, "TS, INF, hello, api, api\n", timestampRe.ReplaceAllString(buf.String(), "TS"))
+}
+
+func TestFilterWriter_ConsoleExcludesRouting(t *testing.T) {
+	buf := &bytes.Buffer{}
+	console := &zerolog.ConsoleWriter{Out: buf, NoColor: true}
+	l, err := filter.New("test", &bytes.Buffer{}, nil, glog.WithWriters(console))
+	assert.NoError(t, err)
+
+	l.TagLog("hello", glog.InfoLevel, nil, 2, glog.LogFields{"id": 1, filter.ValuesFieldName: []string{"a"}})
+	assert.Contains(t, buf.String(), "id=1")
+	assert.NotContains(t, buf.String(), filter.TagFieldName)
+	assert.NotContains(t, buf.String(), filter.ValuesFieldName)
+}
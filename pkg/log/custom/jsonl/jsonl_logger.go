@@ -0,0 +1,83 @@
+package gjsonllog
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"sync"
+
+	log "github.com/omgolab/go-commons/pkg/log"
+	filter "github.com/omgolab/go-commons/pkg/log/custom"
+	"github.com/rs/zerolog"
+)
+
+type JsonlLogger interface {
+	filter.FilterLogger
+	Json(msg string, fields ...log.LogFields)
+	JsonErr(msg string, err error, fields ...log.LogFields)
+}
+
+type jsonlCfg struct {
+	filter.FilterLogger
+	w  io.Writer
+	mu sync.Mutex
+}
+
+func (c *jsonlCfg) Json(msg string, fields ...log.LogFields) {
+	c.TagLog(msg, log.DebugLevel, nil, 3, fields...)
+}
+
+func (c *jsonlCfg) JsonErr(msg string, err error, fields ...log.LogFields) {
+	c.TagLog(msg, log.ErrorLevel, err, 3, fields...)
+}
+
+// WriteRecord implements filter.RecordWriter and writes the record as a json object
+// with the enabled parts (e.g. time, level) next to the event fields
+func (c *jsonlCfg) WriteRecord(r *filter.Record) error {
+	obj := make(map[string]any, len(r.Fields)+5)
+	for k, v := range r.Fields {
+		obj[k] = v
+	}
+
+	if c.IsTimestampFormatterEnabled() && r.Timestamp != "" {
+		obj[zerolog.TimestampFieldName] = r.Timestamp
+	}
+	if c.IsLevelFormatterEnabled() && r.Level != "" {
+		obj[zerolog.LevelFieldName] = r.Level
+	}
+	if c.IsCallerFormatterEnabled() && r.Caller != "" {
+		obj[zerolog.CallerFieldName] = r.Caller
+	}
+	if c.IsErrorFormatterEnabled() && r.Error != "" {
+		obj[zerolog.ErrorFieldName] = r.Error
+	}
+	obj[zerolog.MessageFieldName] = r.Message
+
+	b, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, err = c.w.Write(append(b, '\n'))
+	return err
+}
+
+// New creates a dual logger writing its tagged events to w as json lines
+// and the others to the console output
+func New(w io.Writer, filterOpts []filter.FilterOption, logOpts ...log.LogOption) (JsonlLogger, error) {
+	if w == nil {
+		return nil, errors.New("gjsonllog: writer is nil")
+	}
+
+	c := &jsonlCfg{w: w}
+
+	var err error
+	c.FilterLogger, err = filter.NewRecordLogger("jsonl", c, filterOpts, logOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
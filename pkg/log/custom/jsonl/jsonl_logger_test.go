@@ -0,0 +1,43 @@
+package gjsonllog_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/tj/assert"
+
+	log "github.com/omgolab/go-commons/pkg/log"
+	gjsonllog "github.com/omgolab/go-commons/pkg/log/custom/jsonl"
+)
+
+func TestJsonlLogger(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l, err := gjsonllog.New(buf, nil)
+	assert.NoError(t, err)
+
+	l.Json("created", log.LogFields{"id": 7, "tags": []string{"a"}})
+	l.JsonErr("failed", errors.New("boom"))
+	l.Info("not tagged")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Equal(t, 2, len(lines))
+
+	var first, second map[string]any
+	assert.NoError(t, json.Unmarshal([]byte(lines[0]), &first))
+	assert.NoError(t, json.Unmarshal([]byte(lines[1]), &second))
+
+	assert.Equal(t, "created", first["message"])
+	assert.Equal(t, float64(7), first["id"])
+	assert.Equal(t, []any{"a"}, first["tags"])
+	assert.Equal(t, "DEB", first["level"])
+	assert.NotEmpty(t, first["time"])
+	assert.NotContains(t, lines[0], "tag=")
+
+	assert.Equal(t, "failed", second["message"])
+	assert.Equal(t, "boom", second["error"])
+	assert.Equal(t, "ERR", second["level"])
+	assert.NotContains(t, second, "stack")
+}
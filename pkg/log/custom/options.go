@@ -1,10 +1,12 @@
 package gcustomlog
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/rs/zerolog"
 	"golang.org/x/exp/slices"
@@ -12,13 +14,27 @@ import (
 
 type FilterOption func(*filterWriter) error
 
+// WithDelimiter sets the delimiter ending the parts and fields of a line, the line
+// breaks, the double quote and invalid runes are rejected.
+// Note: the value formatters keep the delimiter set when they're applied, so the default
+// error value still ends with a comma (e.g. "error=boom," with ';')
 func WithDelimiter(delim rune) FilterOption {
 	return func(fw *filterWriter) error {
+		if err := validateDelimiter(delim); err != nil {
+			return err
+		}
 		fw.delimiter = string(delim)
 		return nil
 	}
 }
 
+func validateDelimiter(delim rune) error {
+	if delim == 0 || delim == '\n' || delim == '\r' || delim == '"' || !utf8.ValidRune(delim) || delim == utf8.RuneError {
+		return fmt.Errorf("gcustomlog: invalid delimiter %q", delim)
+	}
+	return nil
+}
+
 func WithTimestampFormatter(f ...string) FilterOption {
 	return func(fw *filterWriter) error {
 		if len(f) == 0 || f[0] == "" {
@@ -45,7 +61,11 @@ func WithLevelFormatter(f ...zerolog.Formatter) FilterOption {
 	return func(fw *filterWriter) error {
 		if len(f) == 0 || f[0] == nil {
 			fw.levelFormatter = func(i interface{}) string {
-				return strings.ToUpper(i.(string))[0:3] + fw.delimiter
+				s, _ := i.(string)
+				if len(s) > 3 {
+					s = s[0:3]
+				}
+				return strings.ToUpper(s) + fw.delimiter
 			}
 			return nil
 		}
@@ -155,13 +175,45 @@ func WithErrFieldValueFormatter(f ...zerolog.Formatter) FilterOption {
 	}
 }
 
+// WithPartsOrder appends the missing parts to the order, a name other than the zerolog parts
+// and the extra columns renders the field of that name
 func WithPartsOrder(o []string) FilterOption {
 	return func(fw *filterWriter) error {
 		for _, v := range o {
 			if !slices.Contains[[]string](fw.partsOrder, v) {
-				fw.partsOrder = append(fw.partsOrder, o...)
+				fw.partsOrder = append(fw.partsOrder, v)
+			}
+		}
+		return nil
+	}
+}
+
+// WithExtraColumns renders the fields as parts of the line (e.g. glog's "context-ns"),
+// after the other parts unless the order already has them. A missing field keeps
+// an empty column.
+func WithExtraColumns(names ...string) FilterOption {
+	return func(fw *filterWriter) error {
+		for _, name := range names {
+			if err := fw.addExtraColumn(name); err != nil {
+				return err
 			}
 		}
 		return nil
 	}
 }
+
+// withExactPartsOrder replaces the parts order, it's used by the FilterBuilder
+func withExactPartsOrder(o []string) FilterOption {
+	return func(fw *filterWriter) error {
+		fw.partsOrder = slices.Clone(o)
+		return nil
+	}
+}
+
+// WithRecordLines renders the console line of the events into Record.Line for the record loggers
+func WithRecordLines() FilterOption {
+	return func(fw *filterWriter) error {
+		fw.recordLines = true
+		return nil
+	}
+}
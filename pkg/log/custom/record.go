@@ -0,0 +1,126 @@
+package gcustomlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	glog "github.com/omgolab/go-commons/pkg/log"
+	"github.com/rs/zerolog"
+)
+
+// Record is a tagged event decoded for a RecordWriter.
+// The parts are formatted by the filter formatters without the console
+// decorations (the trailing delimiter and the caller " >" marker).
+type Record struct {
+	Timestamp string
+	// Time is the event time parsed with zerolog.TimeFieldFormat, zero if it can't be parsed
+	Time    time.Time
+	Level   string
+	Caller  string
+	Message string
+	Error   string
+	// Fields holds the remaining event fields as decoded from json (numbers are json.Number)
+	Fields map[string]any
+	// LogLevel is the event level as parsed from the event
+	LogLevel glog.LogLevel
+	// Line is the console line (without the newline), only set with WithRecordLines
+	Line string
+}
+
+// RecordWriter receives the tagged events as records instead of the console text
+type RecordWriter interface {
+	WriteRecord(r *Record) error
+}
+
+// recordWriter decodes the json events written by zerolog and forwards the tagged ones
+type recordWriter struct {
+	fw *filterWriter
+	w  RecordWriter
+}
+
+// NewRecordLogger creates a filter logger passing its tagged events to w as records,
+// e.g. for the sinks which need the values rather than a formatted line
+func NewRecordLogger(tag string, w RecordWriter, filterOpts []FilterOption, logOpts ...glog.LogOption) (FilterLogger, error) {
+	if tag == "" || w == nil {
+		return nil, errors.New("tag or record writer is invalid")
+	}
+
+	fw, err := newFilterWriter(tag, filterOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	logOpts = append(logOpts, glog.WithMultiLogger(&recordWriter{fw: fw, w: w}), glog.WithConsoleFieldsExcluded(TagFieldName, ValuesFieldName))
+	fw.Logger, err = glog.New(logOpts...)
+	return fw, err
+}
+
+func (rw *recordWriter) Write(b []byte) (int, error) {
+	if !rw.fw.isTagged(b) {
+		return len(b), nil
+	}
+
+	var evt map[string]any
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	if err := d.Decode(&evt); err != nil {
+		return 0, fmt.Errorf("cannot decode event: %w", err)
+	}
+
+	if tag, _ := evt[TagFieldName].(string); tag != rw.fw.tag {
+		return len(b), nil
+	}
+
+	fw := rw.fw
+	msg, hasMessage := evt[zerolog.MessageFieldName].(string)
+	r := &Record{
+		Timestamp: fw.cell(fw.timestampFormatter, evt[zerolog.TimestampFieldName]),
+		Level:     fw.cell(fw.levelFormatter, evt[zerolog.LevelFieldName]),
+		Caller:    fw.cell(fw.callerFormatter, evt[zerolog.CallerFieldName]),
+		Message:   msg,
+	}
+	if ts, ok := evt[zerolog.TimestampFieldName].(string); ok {
+		r.Time, _ = time.Parse(zerolog.TimeFieldFormat, ts)
+	}
+	if lvl, ok := evt[zerolog.LevelFieldName].(string); ok {
+		r.LogLevel, _ = glog.ParseLogLevel(lvl)
+	}
+	if fw.recordLines {
+		buf := &bytes.Buffer{}
+		if err := fw.renderLine(b, hasMessage, buf); err != nil {
+			return 0, err
+		}
+		r.Line = strings.TrimSuffix(buf.String(), "\n")
+	}
+	if _, ok := evt[zerolog.ErrorFieldName]; ok {
+		r.Error = fw.cell(fw.errFieldValueFormatter, evt[zerolog.ErrorFieldName])
+	}
+
+	for k, v := range evt {
+		switch k {
+		case zerolog.TimestampFieldName, zerolog.LevelFieldName, zerolog.CallerFieldName,
+			zerolog.MessageFieldName, zerolog.ErrorFieldName, TagFieldName,
+			glog.ErrorChainFieldName, zerolog.ErrorStackFieldName:
+			continue
+		}
+		if r.Fields == nil {
+			r.Fields = map[string]any{}
+		}
+		r.Fields[k] = v
+	}
+
+	return len(b), rw.w.WriteRecord(r)
+}
+
+// cell formats a record part, a missing part is kept empty
+func (fw *filterWriter) cell(f zerolog.Formatter, v any) string {
+	if v == nil || f == nil {
+		return ""
+	}
+	s := strings.TrimSuffix(f(v), fw.delimiter)
+	return strings.TrimSuffix(s, " >")
+}
@@ -0,0 +1,187 @@
+package gstrlog
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	log "github.com/omgolab/go-commons/pkg/log"
+)
+
+const (
+	// streamBuffer is the number of entries buffered for a slow event stream client
+	streamBuffer = 256
+	// keepAliveInterval is the period of the comments keeping an idle event stream open
+	keepAliveInterval = 15 * time.Second
+)
+
+type entryPayload struct {
+	Time    time.Time      `json:"time"`
+	Level   string         `json:"level"`
+	Line    string         `json:"line,omitempty"`
+	Message string         `json:"message,omitempty"`
+	Caller  string         `json:"caller,omitempty"`
+	Error   string         `json:"error,omitempty"`
+	Fields  map[string]any `json:"fields,omitempty"`
+}
+
+func newEntryPayload(e Entry) entryPayload {
+	return entryPayload{
+		Time:    e.Time,
+		Level:   e.Level.String(),
+		Line:    e.Line,
+		Message: e.Message,
+		Caller:  e.Caller,
+		Error:   e.Error,
+		Fields:  e.Fields,
+	}
+}
+
+type handler struct {
+	l StringLogger
+}
+
+// NewHandler returns an http.Handler serving the captured entries (GET) as plain text lines,
+// or as JSON with `format=json` or an `Accept: application/json` header.
+// The entries are filtered by the `level` (minimum level), `since` (RFC 3339 time or a
+// duration like 5m before now) and `q` (substring) query parameters.
+// An `Accept: text/event-stream` header (or `format=sse`) streams the matching entries as
+// Server-Sent Events, starting with the captured ones.
+func NewHandler(l StringLogger) http.Handler {
+	return &handler{l: l}
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	q, err := parseQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	switch format(r) {
+	case "sse":
+		h.stream(w, r, q)
+	case "json":
+		payload := []entryPayload{}
+		for _, e := range h.l.Filter(q) {
+			payload = append(payload, newEntryPayload(e))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(payload)
+	default:
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		for _, e := range h.l.Filter(q) {
+			fmt.Fprintln(w, e.String())
+		}
+	}
+}
+
+// stream writes the matching entries as events until the client goes away
+func (h *handler) stream(w http.ResponseWriter, r *http.Request, q Query) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "gstrlog: streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	// subscribe first so no entry is missed between the snapshot and the stream
+	entries, cancel := h.l.Subscribe(streamBuffer)
+	defer cancel()
+	backlog := h.l.Filter(q)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	// the entries captured meanwhile are in both, the sequence skips them in the stream
+	var last uint64
+	if n := len(backlog); n > 0 {
+		last = backlog[n-1].Seq
+	}
+	for _, e := range backlog {
+		if writeEvent(w, e) != nil {
+			return
+		}
+	}
+	flusher.Flush()
+
+	ticker := time.NewTicker(keepAliveInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		case e := <-entries:
+			// skip the entries already sent with the backlog
+			if !q.Match(e) || e.Seq <= last {
+				continue
+			}
+			if writeEvent(w, e) != nil {
+				return
+			}
+		}
+		flusher.Flush()
+	}
+}
+
+func writeEvent(w http.ResponseWriter, e Entry) error {
+	b, err := json.Marshal(newEntryPayload(e))
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "data: %s\n\n", b)
+	return err
+}
+
+func format(r *http.Request) string {
+	if f := r.URL.Query().Get("format"); f != "" {
+		return strings.ToLower(f)
+	}
+
+	accept := r.Header.Get("Accept")
+	switch {
+	case strings.Contains(accept, "text/event-stream"):
+		return "sse"
+	case strings.Contains(accept, "application/json"):
+		return "json"
+	}
+	return "text"
+}
+
+func parseQuery(r *http.Request) (Query, error) {
+	params := r.URL.Query()
+	q := Query{Contains: params.Get("q")}
+
+	if s := params.Get("level"); s != "" {
+		level, err := log.ParseLogLevel(s)
+		if err != nil {
+			return q, err
+		}
+		q.MinLevel = level
+	}
+
+	if s := params.Get("since"); s != "" {
+		if d, err := time.ParseDuration(s); err == nil {
+			q.Since = time.Now().Add(-d)
+		} else if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+			q.Since = t
+		} else {
+			return q, fmt.Errorf("gstrlog: invalid since %q, expected a RFC 3339 time or a duration", s)
+		}
+	}
+
+	return q, nil
+}
@@ -0,0 +1,95 @@
+package gstrlog_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/tj/assert"
+
+	gstrlog "github.com/omgolab/go-commons/pkg/log/custom/string"
+)
+
+func TestHandler(t *testing.T) {
+	l, _ := gstrlog.New(nil)
+	l.AppendString("cache warmed")
+	l.AppendStringErr("cache failed", errors.New("disk full"))
+	l.AppendString("request served")
+	h := gstrlog.NewHandler(l)
+
+	t.Run("text", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?q=cache", nil))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "text/plain; charset=utf-8", rec.Header().Get("Content-Type"))
+		lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+		assert.Equal(t, 2, len(lines))
+		assert.Contains(t, lines[1], "cache failed")
+	})
+
+	t.Run("json", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/?level=error&since=1m", nil)
+		req.Header.Set("Accept", "application/json")
+		h.ServeHTTP(rec, req)
+
+		var entries []map[string]any
+		assert.NoError(t, json.NewDecoder(rec.Body).Decode(&entries))
+		assert.Equal(t, 1, len(entries))
+		assert.Equal(t, "error", entries[0]["level"])
+		assert.Contains(t, entries[0]["line"], "disk full")
+	})
+
+	t.Run("invalid filters", func(t *testing.T) {
+		for _, target := range []string{"/?level=loud", "/?since=yesterday"} {
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+			assert.Equal(t, http.StatusBadRequest, rec.Code, target)
+		}
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", nil))
+		assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	})
+}
+
+func TestHandler_Stream(t *testing.T) {
+	l, _ := gstrlog.New(nil)
+	l.AppendString("before")
+	l.AppendString("match 0")
+	srv := httptest.NewServer(gstrlog.NewHandler(l))
+	defer srv.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/?q=match", nil)
+	req.Header.Set("Accept", "text/event-stream")
+	res, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer res.Body.Close()
+	assert.Equal(t, "text/event-stream", res.Header.Get("Content-Type"))
+
+	// the entries logged within the same second as the backlog are streamed too
+	l.AppendString("skipped")
+	l.AppendString("match 1")
+	l.AppendString("match 2")
+
+	s := bufio.NewScanner(res.Body)
+	var got []string
+	for len(got) < 3 && s.Scan() {
+		line := s.Text()
+		if !strings.HasPrefix(line, "data: ") {
+			continue
+		}
+		var e map[string]any
+		assert.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &e))
+		got = append(got, e["line"].(string))
+	}
+	assert.Equal(t, 3, len(got))
+	assert.Contains(t, got[0], "match 0")
+	assert.Contains(t, got[1], "match 1")
+	assert.Contains(t, got[2], "match 2")
+}
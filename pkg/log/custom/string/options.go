@@ -0,0 +1,25 @@
+package gstrlog
+
+import "errors"
+
+type StringOption func(*arrayCfg) error
+
+// WithCapacity sets the number of entries kept (default: DefaultCapacity), the oldest are dropped first
+func WithCapacity(n int) StringOption {
+	return func(c *arrayCfg) error {
+		if n <= 0 {
+			return errors.New("gstrlog: the capacity must be positive")
+		}
+		c.capacity = n
+		return nil
+	}
+}
+
+// WithStructuredEntries keeps the parsed message, caller, error and fields
+// of the entries instead of their formatted lines
+func WithStructuredEntries() StringOption {
+	return func(c *arrayCfg) error {
+		c.structured = true
+		return nil
+	}
+}
@@ -2,30 +2,207 @@ package gstrlog
 
 import (
 	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	log "github.com/omgolab/go-commons/pkg/log"
 	filter "github.com/omgolab/go-commons/pkg/log/custom"
 )
 
+// DefaultCapacity is the number of entries kept by default, the oldest are dropped first
+const DefaultCapacity = 10_000
+
 type StringLogger interface {
 	filter.FilterLogger
+	// GetStringLogs returns a copy of the captured lines (newline terminated), oldest first
 	GetStringLogs() []string
 	AppendString(str string)
 	AppendStringErr(str string, err error)
+	// Snapshot returns a copy of the captured entries, oldest first
+	Snapshot() []Entry
+	// Filter returns the captured entries matching q, oldest first
+	Filter(q Query) []Entry
+	Clear()
+	// Subscribe returns a channel receiving the new entries until cancel is called,
+	// the entries are dropped while its buffer is full
+	Subscribe(buffer int) (entries <-chan Entry, cancel func())
+}
+
+// Entry is a captured event. Line holds the formatted line, unless the
+// structured entries are stored (see WithStructuredEntries) which keep the parsed parts instead.
+type Entry struct {
+	// Seq is the capture order, starting at 1
+	Seq     uint64
+	Time    time.Time
+	Level   log.LogLevel
+	Line    string
+	Message string
+	Caller  string
+	Error   string
+	Fields  map[string]any
+}
+
+// String returns the line or a rendering of the structured entry
+func (e Entry) String() string {
+	if e.Line != "" {
+		return e.Line
+	}
+
+	sb := strings.Builder{}
+	sb.WriteString(e.Time.Format(time.RFC3339))
+	sb.WriteString(" " + e.Level.String() + " ")
+	if e.Caller != "" {
+		sb.WriteString(e.Caller + " > ")
+	}
+	sb.WriteString(e.Message)
+
+	keys := make([]string, 0, len(e.Fields))
+	for k := range e.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(&sb, " %s=%v", k, e.Fields[k])
+	}
+	if e.Error != "" {
+		sb.WriteString(" error=" + e.Error)
+	}
+	return sb.String()
+}
+
+// Query selects the entries, the zero value matches all of them
+type Query struct {
+	// MinLevel is the lowest level to match
+	MinLevel log.LogLevel
+	// Contains is matched against the line or the message and error of a structured entry
+	Contains string
+	// Since and Until bound the entry time, inclusive
+	Since, Until time.Time
+}
+
+// Match reports whether the entry is selected
+func (q Query) Match(e Entry) bool {
+	if e.Level < q.MinLevel {
+		return false
+	}
+	if !q.Since.IsZero() && e.Time.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && e.Time.After(q.Until) {
+		return false
+	}
+	if q.Contains == "" {
+		return true
+	}
+	if e.Line != "" {
+		return strings.Contains(e.Line, q.Contains)
+	}
+	return strings.Contains(e.Message, q.Contains) || strings.Contains(e.Error, q.Contains)
 }
 
 type arrayCfg struct {
 	filter.FilterLogger
-	data []string
+	capacity   int
+	structured bool
+
+	// data is a ring buffer: the oldest entry is at head once it's full
+	mu   sync.Mutex
+	data []Entry
+	head int
+	seq  uint64
+	subs map[chan Entry]struct{}
 }
 
-func (c *arrayCfg) Write(b []byte) (n int, err error) {
-	c.data = append(c.data, string(b))
-	return len(b), nil
+// WriteRecord implements filter.RecordWriter and captures the record
+func (c *arrayCfg) WriteRecord(r *filter.Record) error {
+	// the event time, or the capture time when the timestamps are disabled
+	e := Entry{Time: r.Time, Level: r.LogLevel}
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	if c.structured {
+		e.Message, e.Caller, e.Error, e.Fields = r.Message, r.Caller, r.Error, r.Fields
+	} else {
+		e.Line = r.Line
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.seq++
+	e.Seq = c.seq
+	for ch := range c.subs {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+
+	if len(c.data) < c.capacity {
+		c.data = append(c.data, e)
+		return nil
+	}
+	c.data[c.head] = e
+	c.head = (c.head + 1) % c.capacity
+	return nil
+}
+
+func (c *arrayCfg) Subscribe(buffer int) (<-chan Entry, func()) {
+	ch := make(chan Entry, buffer)
+
+	c.mu.Lock()
+	if c.subs == nil {
+		c.subs = map[chan Entry]struct{}{}
+	}
+	c.subs[ch] = struct{}{}
+	c.mu.Unlock()
+
+	once := sync.Once{}
+	return ch, func() {
+		once.Do(func() {
+			c.mu.Lock()
+			delete(c.subs, ch)
+			c.mu.Unlock()
+			close(ch)
+		})
+	}
 }
 
 func (c *arrayCfg) GetStringLogs() []string {
-	return c.data
+	entries := c.Snapshot()
+	logs := make([]string, len(entries))
+	for i, e := range entries {
+		logs[i] = e.String() + "\n"
+	}
+	return logs
+}
+
+func (c *arrayCfg) Snapshot() []Entry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entries := make([]Entry, 0, len(c.data))
+	entries = append(entries, c.data[c.head:]...)
+	return append(entries, c.data[:c.head]...)
+}
+
+func (c *arrayCfg) Filter(q Query) []Entry {
+	entries := c.Snapshot()
+	matched := entries[:0]
+	for _, e := range entries {
+		if q.Match(e) {
+			matched = append(matched, e)
+		}
+	}
+	return matched
+}
+
+func (c *arrayCfg) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.data = c.data[:0]
+	c.head = 0
 }
 
 func (c *arrayCfg) AppendString(str string) {
@@ -37,21 +214,36 @@ func (c *arrayCfg) AppendStringErr(str string, err error) {
 }
 
 // New creates a new instance of the StringLogger interface, which is a logger implementation that logs
-// messages to an array and allows retrieving the logged messages.
+// messages to a bounded in-memory buffer and allows retrieving the logged messages.
 func New(filterOpts []filter.FilterOption, logOpts ...log.LogOption) (StringLogger, error) {
+	return NewWithOptions(nil, filterOpts, logOpts...)
+}
+
+// NewWithOptions is New with the buffer options, e.g. WithCapacity or WithStructuredEntries
+func NewWithOptions(opts []StringOption, filterOpts []filter.FilterOption, logOpts ...log.LogOption) (StringLogger, error) {
 	// Create a new instance of the arrayCfg struct
-	sl := &arrayCfg{}
+	sl := &arrayCfg{capacity: DefaultCapacity}
+	for _, opt := range opts {
+		if err := opt(sl); err != nil {
+			return nil, err
+		}
+	}
+
+	// the lines are rendered by the filter writer unless the entries are structured
+	if !sl.structured {
+		filterOpts = append([]filter.FilterOption{filter.WithRecordLines()}, filterOpts...)
+	}
 
 	// Create a new filter logger with the provided options
 	var err error
-	sl.FilterLogger, err = filter.New("string-log", sl, filterOpts, logOpts...)
+	sl.FilterLogger, err = filter.NewRecordLogger("string-log", sl, filterOpts, logOpts...)
 	if err != nil {
 		return nil, err
 	}
 
 	// Check if the filter logger is nil
 	if sl.FilterLogger == nil {
-		return nil, errors.New("filter.New returned a nil FilterLogger")
+		return nil, errors.New("filter.NewRecordLogger returned a nil FilterLogger")
 	}
 
 	return sl, nil
@@ -2,7 +2,10 @@ package gstrlog_test
 
 import (
 	"errors"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	log "github.com/omgolab/go-commons/pkg/log"
 	"github.com/tj/assert"
@@ -46,3 +49,74 @@ func TestStringLogger(t *testing.T) {
 		assert.Contains(t, logs[2], "second message")
 	})
 }
+
+func TestStringLogger_Ring(t *testing.T) {
+	l, err := gstrlog.NewWithOptions([]gstrlog.StringOption{gstrlog.WithCapacity(2)}, nil)
+	assert.NoError(t, err)
+
+	l.AppendString("first")
+	l.AppendString("second")
+	l.AppendStringErr("third", errors.New("boom"))
+	l.Info("not tagged")
+
+	entries := l.Snapshot()
+	assert.Equal(t, 2, len(entries))
+	assert.Contains(t, entries[0].Line, "second")
+	assert.Contains(t, entries[1].Line, "third")
+	assert.Equal(t, log.ErrorLevel, entries[1].Level)
+
+	logs := l.GetStringLogs()
+	assert.True(t, strings.HasSuffix(logs[1], "boom\n"))
+
+	l.Clear()
+	assert.Empty(t, l.Snapshot())
+	l.AppendString("fourth")
+	assert.Equal(t, 1, len(l.GetStringLogs()))
+}
+
+func TestStringLogger_Filter(t *testing.T) {
+	l, _ := gstrlog.NewWithOptions([]gstrlog.StringOption{gstrlog.WithStructuredEntries()}, nil)
+
+	l.AppendString("cache warmed")
+	start := time.Now()
+	l.AppendStringErr("cache failed", errors.New("disk full"))
+	l.TagLog("request", log.InfoLevel, nil, 2, log.LogFields{"path": "/x"})
+
+	errs := l.Filter(gstrlog.Query{MinLevel: log.ErrorLevel})
+	assert.Equal(t, 1, len(errs))
+	assert.Equal(t, "cache failed", errs[0].Message)
+	assert.Equal(t, "disk full", errs[0].Error)
+	assert.Empty(t, errs[0].Line)
+
+	assert.Equal(t, 2, len(l.Filter(gstrlog.Query{Contains: "cache"})))
+	assert.Equal(t, 1, len(l.Filter(gstrlog.Query{Contains: "disk"})))
+	// the entries carry the event time, written with the seconds precision by default
+	from := start.Truncate(time.Second)
+	assert.Equal(t, 3, len(l.Filter(gstrlog.Query{Since: from})))
+	assert.Equal(t, 0, len(l.Filter(gstrlog.Query{Since: from.Add(2 * time.Second)})))
+	assert.Equal(t, 0, len(l.Filter(gstrlog.Query{Until: from.Add(-time.Second)})))
+	assert.True(t, from.Equal(errs[0].Time.Truncate(time.Second)))
+
+	req := l.Filter(gstrlog.Query{MinLevel: log.InfoLevel, Contains: "request"})
+	assert.Equal(t, 1, len(req))
+	assert.Equal(t, "/x", req[0].Fields["path"])
+	assert.Contains(t, req[0].String(), "info request path=/x")
+}
+
+func TestStringLogger_Concurrent(t *testing.T) {
+	l, _ := gstrlog.NewWithOptions([]gstrlog.StringOption{gstrlog.WithCapacity(50)}, nil)
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				l.AppendString("msg")
+				_ = l.GetStringLogs()
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 50, len(l.Snapshot()))
+}
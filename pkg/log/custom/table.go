@@ -0,0 +1,78 @@
+package gcustomlog
+
+import (
+	"strings"
+
+	glog "github.com/omgolab/go-commons/pkg/log"
+)
+
+// ValuesFieldName is the field carrying the row values of the tabular loggers (e.g. csv or tsv),
+// it's hidden from the console which shows the values as the message
+const ValuesFieldName = "row-values"
+
+// TagRow logs the row values of a tabular logger: the sink reads them from ValuesFieldName
+// and the console shows them joined with the delimiter as the message
+func TagRow(l FilterLogger, level glog.LogLevel, err error, csfCount int, values []string) {
+	l.TagLog(strings.Join(values, l.GetDelimiter()), level, err, csfCount+1, glog.LogFields{ValuesFieldName: values})
+}
+
+// JoinExtraValues is the overflow rule of the tabular loggers (csv, tsv and binary): the values
+// beyond the n columns are joined with sep into the last column to keep the column count of the file
+func JoinExtraValues(values []string, n int, sep string) []string {
+	if n == 0 || len(values) <= n {
+		return values
+	}
+	last := strings.Join(values[n-1:], sep)
+	return append(values[:n-1:n-1], last)
+}
+
+// Columns returns the header of a tabular file: the enabled log parts around the given headers
+func Columns(l FilterLogger, headers []string) []string {
+	columns := []string{}
+	if l.IsTimestampFormatterEnabled() {
+		columns = append(columns, "Timestamp")
+	}
+	if l.IsLevelFormatterEnabled() {
+		columns = append(columns, "Level")
+	}
+	if l.IsCallerFormatterEnabled() {
+		columns = append(columns, "Caller")
+	}
+
+	// append message headers
+	columns = append(columns, headers...)
+
+	// finally append error headers
+	if l.IsErrorFormatterEnabled() {
+		columns = append(columns, "Error")
+	}
+	return columns
+}
+
+// Row returns the record as a row matching Columns for n headers,
+// the values are read from ValuesFieldName and the missing ones are left empty
+func Row(l FilterLogger, r *Record, n int) []string {
+	row := make([]string, 0, n+4)
+	if l.IsTimestampFormatterEnabled() {
+		row = append(row, r.Timestamp)
+	}
+	if l.IsLevelFormatterEnabled() {
+		row = append(row, r.Level)
+	}
+	if l.IsCallerFormatterEnabled() {
+		row = append(row, r.Caller)
+	}
+
+	values := make([]string, n)
+	if vs, ok := r.Fields[ValuesFieldName].([]any); ok {
+		for i := 0; i < len(vs) && i < len(values); i++ {
+			values[i], _ = vs[i].(string)
+		}
+	}
+	row = append(row, values...)
+
+	if l.IsErrorFormatterEnabled() {
+		row = append(row, r.Error)
+	}
+	return row
+}
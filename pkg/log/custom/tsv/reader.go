@@ -0,0 +1,54 @@
+package gtsvlog
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Record is a tsv log row keyed by the column names of the file header
+type Record map[string]string
+
+// ReadFile parses all the records of a tsv log file
+func ReadFile(path string) ([]Record, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	s := bufio.NewScanner(f)
+	s.Buffer(nil, 1<<20)
+	if !s.Scan() {
+		if err := s.Err(); err != nil {
+			return nil, err
+		}
+		return nil, errors.New("gtsvlog: missing tsv header")
+	}
+	header := split(s.Text())
+
+	var recs []Record
+	for line := 2; s.Scan(); line++ {
+		row := split(s.Text())
+		if len(row) != len(header) {
+			return recs, fmt.Errorf("gtsvlog: line %d: %d values for %d columns", line, len(row), len(header))
+		}
+
+		rec := make(Record, len(row))
+		for i, v := range row {
+			rec[header[i]] = v
+		}
+		recs = append(recs, rec)
+	}
+	return recs, s.Err()
+}
+
+func split(line string) []string {
+	row := strings.Split(line, "\t")
+	for i, v := range row {
+		row[i] = unescaper.Replace(v)
+	}
+	return row
+}
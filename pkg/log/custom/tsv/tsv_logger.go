@@ -0,0 +1,99 @@
+package gtsvlog
+
+import (
+	"os"
+	"strings"
+	"sync"
+
+	fo "github.com/omgolab/go-commons/pkg/file/open"
+	log "github.com/omgolab/go-commons/pkg/log"
+	filter "github.com/omgolab/go-commons/pkg/log/custom"
+)
+
+type TsvLogger interface {
+	filter.FilterLogger
+	FileName() string
+	Close() error
+	// Tsv writes a row, the extra values are joined with a tab into the last column
+	Tsv(str ...string)
+	TsvErr(err error, str ...string)
+}
+
+type tsvCfg struct {
+	filter.FilterLogger
+	file    *os.File
+	headers []string
+	mu      sync.Mutex
+}
+
+// tabs, newlines and backslashes can't be part of a tsv value so they are escaped
+var (
+	escaper   = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`)
+	unescaper = strings.NewReplacer(`\\`, `\`, `\t`, "\t", `\n`, "\n", `\r`, "\r")
+)
+
+func (c *tsvCfg) FileName() string {
+	return c.file.Name()
+}
+
+func (c *tsvCfg) Tsv(str ...string) {
+	c.tsv(log.DebugLevel, nil, str)
+}
+
+func (c *tsvCfg) TsvErr(err error, str ...string) {
+	c.tsv(log.ErrorLevel, err, str)
+}
+
+// Close closes the file, the rows logged afterwards are lost
+func (c *tsvCfg) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.file.Close()
+}
+
+func (c *tsvCfg) tsv(level log.LogLevel, err error, values []string) {
+	filter.TagRow(c, level, err, 4, filter.JoinExtraValues(values, len(c.headers), "\t"))
+}
+
+// WriteRecord implements filter.RecordWriter and writes the record as a tsv row
+func (c *tsvCfg) WriteRecord(r *filter.Record) error {
+	return c.writeRow(filter.Row(c, r, len(c.headers)))
+}
+
+func (c *tsvCfg) writeRow(row []string) error {
+	for i, v := range row {
+		row[i] = escaper.Replace(v)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, err := c.file.WriteString(strings.Join(row, "\t") + "\n")
+	return err
+}
+
+// New creates a dual logger with tsv and console output, the rows are appended to an existing file
+func New(tsvPath string, headers []string, filterOpts []filter.FilterOption, logOpts ...log.LogOption) (TsvLogger, error) {
+	c := &tsvCfg{headers: headers}
+
+	var err error
+	c.FilterLogger, err = filter.NewRecordLogger("tsv", c, filterOpts, logOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	c.file, err = fo.OpenFile(tsvPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// write the headers if the file is empty
+	s, err := c.file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if s.Size() == 0 {
+		err = c.writeRow(filter.Columns(c, headers))
+	}
+
+	return c, err
+}
@@ -0,0 +1,36 @@
+package gtsvlog_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/tj/assert"
+
+	gtsvlog "github.com/omgolab/go-commons/pkg/log/custom/tsv"
+)
+
+func TestTsvLogger(t *testing.T) {
+	path := t.TempDir() + "/report.tsv"
+	l, err := gtsvlog.New(path, []string{"Name", "Note"}, nil)
+	assert.NoError(t, err)
+
+	l.Tsv("a\tb", `back\slash`)
+	l.TsvErr(errors.New("line\nbreak"), "c")
+	l.Tsv("too", "many", "values")
+	l.Info("not tagged")
+
+	assert.NoError(t, l.Close())
+
+	recs, err := gtsvlog.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(recs))
+
+	assert.Equal(t, "a\tb", recs[0]["Name"])
+	assert.Equal(t, `back\slash`, recs[0]["Note"])
+	assert.Equal(t, "DEB", recs[0]["Level"])
+	assert.Equal(t, "c", recs[1]["Name"])
+	assert.Equal(t, "", recs[1]["Note"])
+	assert.Equal(t, "line\nbreak", recs[1]["Error"])
+	// the extra values are kept in the last column
+	assert.Equal(t, "many\tvalues", recs[2]["Note"])
+}
@@ -0,0 +1,103 @@
+package glog
+
+import (
+	"encoding/json"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// RepeatedFieldName is the field holding the number of collapsed duplicates
+const RepeatedFieldName = "repeated"
+
+type dedupKey struct {
+	level  LogLevel
+	ns     string
+	msg    string
+	err    string
+	fields string
+}
+
+// newDedupKey identifies an event by its level, namespace, message, error and fields,
+// e.g. the csv rows have no message and carry their values in a field.
+// The stack is left out so the same error from another call site is still a duplicate.
+func newDedupKey(level LogLevel, ns, msg string, err error, fields LogFields) dedupKey {
+	k := dedupKey{level: level, ns: ns, msg: msg}
+	if err != nil {
+		k.err = err.Error()
+	}
+	if len(fields) > 0 {
+		if _, ok := fields[zerolog.ErrorStackFieldName]; ok {
+			fields = maps.Clone(fields)
+			delete(fields, zerolog.ErrorStackFieldName)
+		}
+		// json sorts the map keys, the marshaling error stands for the unsupported values
+		b, jerr := json.Marshal(fields)
+		if jerr != nil {
+			b = []byte(jerr.Error())
+		}
+		k.fields = string(b)
+	}
+	return k
+}
+
+// deduper collapses the identical events within a window: the first one is written
+// right away and the duplicates are written once as the last of them with a repeated=N field
+// when the window closes or a different event arrives
+type deduper struct {
+	window time.Duration
+
+	mu       sync.Mutex
+	key      dedupKey
+	isActive bool
+	repeated int
+	last     func(extra LogFields)
+	timer    *time.Timer
+	gen      uint64
+}
+
+// observe returns false if the event is a duplicate to hold back
+func (d *deduper) observe(key dedupKey, emit func(extra LogFields)) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.isActive && d.key == key {
+		d.repeated++
+		d.last = emit
+		return false
+	}
+
+	d.flushLocked()
+	d.key, d.isActive = key, true
+	gen := d.gen
+	d.timer = time.AfterFunc(d.window, func() {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		// skip if the window was already closed by a newer event
+		if d.gen == gen {
+			d.flushLocked()
+		}
+	})
+	return true
+}
+
+func (d *deduper) flush() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.flushLocked()
+}
+
+// flushLocked writes the held back duplicates and closes the window
+func (d *deduper) flushLocked() {
+	if d.timer != nil {
+		d.timer.Stop()
+		d.timer = nil
+	}
+	if d.repeated > 0 {
+		d.last(LogFields{RepeatedFieldName: d.repeated})
+	}
+	d.isActive, d.repeated, d.last = false, 0, nil
+	d.gen++
+}
@@ -0,0 +1,45 @@
+package glog_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	glog "github.com/omgolab/go-commons/pkg/log"
+	glogtest "github.com/omgolab/go-commons/pkg/log/test"
+)
+
+func TestDedup(t *testing.T) {
+	l := glogtest.New(t, glog.WithDedup(50*time.Millisecond))
+	err := errors.New("connection refused")
+
+	for i := 0; i < 5; i++ {
+		l.Error("retrying", err, glog.LogFields{"host": "db"})
+	}
+	l.Info("different")
+	// the fields are part of the identity, e.g. the csv rows have no message
+	l.Info("row", glog.LogFields{"values": []string{"1"}})
+	l.Info("row", glog.LogFields{"values": []string{"2"}})
+
+	for i := 0; i < 3; i++ {
+		l.Warn("slow")
+	}
+	time.Sleep(150 * time.Millisecond)
+
+	entries := l.Entries()
+	if len(entries) != 7 {
+		for _, e := range entries {
+			t.Log(e.Raw)
+		}
+		t.Fatalf("expected 7 entries, got %d", len(entries))
+	}
+
+	l.AssertLogged(t, glog.ErrorLevel, "retrying", glog.LogFields{"host": "db"})
+	l.AssertLogged(t, glog.ErrorLevel, "retrying", glog.LogFields{"host": "db", glog.RepeatedFieldName: 4})
+	l.AssertLogged(t, glog.InfoLevel, "row", glog.LogFields{"values": []any{"2"}})
+	l.AssertLogged(t, glog.InfoLevel, "different", nil)
+	l.AssertLogged(t, glog.WarnLevel, "slow", glog.LogFields{glog.RepeatedFieldName: 2})
+	if entries[1].Caller == "" || entries[1].Caller != entries[0].Caller {
+		t.Errorf("expected the repeated entry to keep the caller, got %q", entries[1].Caller)
+	}
+}
@@ -0,0 +1,169 @@
+package glog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/pkgerrors"
+)
+
+// ErrorChainFieldName is the field holding the messages of the wrapped/joined errors
+const ErrorChainFieldName = "error-chain"
+
+const maxStackDepth = 32
+
+// FieldsError is implemented by errors carrying structured fields to log along with them
+type FieldsError interface {
+	error
+	LogFields() LogFields
+}
+
+type stackError struct {
+	error
+	pcs []uintptr
+}
+
+func (e *stackError) Unwrap() error {
+	return e.error
+}
+
+// ErrorWithStack wraps err with the stack trace of the caller unless it already has one
+func ErrorWithStack(err error) error {
+	if err == nil || hasStack(err) {
+		return err
+	}
+	return &stackError{error: err, pcs: callers(3)}
+}
+
+func callers(skip int) []uintptr {
+	pcs := make([]uintptr, maxStackDepth)
+	return pcs[:runtime.Callers(skip, pcs)]
+}
+
+func hasStack(err error) bool {
+	var se *stackError
+	return errors.As(err, &se) || pkgerrors.MarshalStack(err) != nil
+}
+
+// errorStack returns the stack attached to err, or the captured pcs if it has none,
+// as frames in the same format as pkgerrors.MarshalStack
+func errorStack(err error, fallback []uintptr) any {
+	var se *stackError
+	if errors.As(err, &se) {
+		return marshalFrames(se.pcs)
+	}
+	if st := pkgerrors.MarshalStack(err); st != nil {
+		return st
+	}
+	return marshalFrames(fallback)
+}
+
+func marshalFrames(pcs []uintptr) []map[string]string {
+	out := make([]map[string]string, 0, len(pcs))
+	frames := runtime.CallersFrames(pcs)
+	for {
+		f, more := frames.Next()
+		if f.Function != "" {
+			fn := f.Function
+			if i := strings.LastIndexByte(fn, '/'); i >= 0 {
+				fn = fn[i+1:]
+			}
+			out = append(out, map[string]string{
+				"source": filepath.Base(f.File),
+				"line":   strconv.Itoa(f.Line),
+				"func":   fn,
+			})
+		}
+		if !more {
+			return out
+		}
+	}
+}
+
+// errorChain walks the errors.Unwrap and errors.Join tree depth first
+func errorChain(err error) []error {
+	var chain []error
+	var walk func(error)
+	walk = func(e error) {
+		for e != nil {
+			if _, ok := e.(*stackError); !ok {
+				chain = append(chain, e)
+			}
+			switch u := e.(type) {
+			case interface{ Unwrap() []error }:
+				for _, je := range u.Unwrap() {
+					walk(je)
+				}
+				return
+			case interface{ Unwrap() error }:
+				e = u.Unwrap()
+			default:
+				return
+			}
+		}
+	}
+	walk(err)
+	return chain
+}
+
+// chainMessages skips the messages repeated by wrappers not adding any text
+func chainMessages(chain []error) []string {
+	msgs := make([]string, 0, len(chain))
+	for _, e := range chain {
+		msg := e.Error()
+		if len(msgs) > 0 && msgs[len(msgs)-1] == msg {
+			continue
+		}
+		msgs = append(msgs, msg)
+	}
+	return msgs
+}
+
+// chainFields merges the fields of every FieldsError in the chain, the outer errors win
+func chainFields(chain []error) LogFields {
+	var fields LogFields
+	for i := len(chain) - 1; i >= 0; i-- {
+		fe, ok := chain[i].(FieldsError)
+		if !ok {
+			continue
+		}
+		for k, v := range fe.LogFields() {
+			if fields == nil {
+				fields = LogFields{}
+			}
+			fields[k] = v
+		}
+	}
+	return fields
+}
+
+// formatErrorDetails renders the error chain and stack on separate lines for the console writer
+func formatErrorDetails(evt map[string]any, buf *bytes.Buffer) error {
+	writeErrorDetails(buf, evt, func(s, _ string) string { return s })
+	return nil
+}
+
+// writeErrorDetails renders the error chain and stack of the event on separate lines,
+// colorize decorates the labels and frames (see PrettyWriter)
+func writeErrorDetails(buf *bytes.Buffer, evt map[string]any, colorize func(s, color string) string) {
+	if chain, ok := evt[ErrorChainFieldName].([]any); ok && len(chain) > 1 {
+		for _, msg := range chain[1:] {
+			text := strings.ReplaceAll(fmt.Sprint(msg), "\n", "\n             ")
+			buf.WriteString("\n  " + colorize("caused by:", colorRed) + " " + text)
+		}
+	}
+	if stack, ok := evt[zerolog.ErrorStackFieldName].([]any); ok {
+		for _, frame := range stack {
+			if f, ok := frame.(map[string]any); ok {
+				line := fmt.Sprintf("at %v (%v:%v)", f["func"], f["source"], f["line"])
+				buf.WriteString("\n    " + colorize(line, colorGray))
+			}
+		}
+	}
+}
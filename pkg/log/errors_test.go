@@ -0,0 +1,82 @@
+package glog_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	glog "github.com/omgolab/go-commons/pkg/log"
+)
+
+type fieldsErr struct {
+	error
+	fields glog.LogFields
+}
+
+func (e fieldsErr) LogFields() glog.LogFields {
+	return e.fields
+}
+
+func (e fieldsErr) Unwrap() error {
+	return e.error
+}
+
+func TestErrorLogging_ChainFieldsAndStack(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l, err := glog.New(glog.WithMultiLogger(buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	inner := fieldsErr{error: errors.New("disk full"), fields: glog.LogFields{"device": "sda", "code": 1}}
+	joined := errors.Join(inner, errors.New("retry failed"))
+	outer := fieldsErr{error: fmt.Errorf("save: %w", joined), fields: glog.LogFields{"code": 2}}
+	l.Error("failed", outer, glog.LogFields{"user": "bob"})
+
+	var got map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+
+	chain, _ := got[glog.ErrorChainFieldName].([]any)
+	want := []string{"save: disk full\nretry failed", "disk full\nretry failed", "disk full", "retry failed"}
+	if len(chain) != len(want) {
+		t.Fatalf("expected chain %q, got %q", want, chain)
+	}
+	for i := range want {
+		if chain[i] != want[i] {
+			t.Errorf("chain[%d]: expected %q, got %q", i, want[i], chain[i])
+		}
+	}
+
+	if got["device"] != "sda" || got["code"] != float64(2) || got["user"] != "bob" {
+		t.Errorf("unexpected error fields: %v", got)
+	}
+
+	stack, _ := got["stack"].([]any)
+	if len(stack) == 0 {
+		t.Fatalf("expected a stack, got %v", got["stack"])
+	}
+	if top, _ := stack[0].(map[string]any); top["source"] != "errors_test.go" {
+		t.Errorf("expected the stack to start at the log call, got %v", top)
+	}
+}
+
+func TestErrorLogging_ErrorWithStackKeepsCreationSite(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l, _ := glog.New(glog.WithMultiLogger(buf))
+
+	err := newStackErr()
+	l.Error("failed", fmt.Errorf("wrapped: %w", err))
+
+	if !strings.Contains(buf.String(), `"func":"log_test.newStackErr"`) {
+		t.Errorf("expected the stack of the creation site, got %s", buf.String())
+	}
+}
+
+func newStackErr() error {
+	return glog.ErrorWithStack(errors.New("boom"))
+}
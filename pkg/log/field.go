@@ -0,0 +1,241 @@
+package glog
+
+import (
+	"context"
+	"math"
+	"runtime"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+type fieldKind uint8
+
+const (
+	anyKind fieldKind = iota
+	stringKind
+	intKind
+	uintKind
+	floatKind
+	boolKind
+	durationKind
+	timeKind
+)
+
+// Field is a typed key/value pair for the allocation free logging path
+// of TypedLogger. It's built with Str, Int, Float64, etc.
+type Field struct {
+	Key   string
+	kind  fieldKind
+	num   int64
+	str   string
+	iface any
+}
+
+// Str returns a string field
+func Str(key, value string) Field {
+	return Field{Key: key, kind: stringKind, str: value}
+}
+
+// Int returns an int field
+func Int(key string, value int) Field {
+	return Field{Key: key, kind: intKind, num: int64(value)}
+}
+
+// Int64 returns an int64 field
+func Int64(key string, value int64) Field {
+	return Field{Key: key, kind: intKind, num: value}
+}
+
+// Uint64 returns an uint64 field
+func Uint64(key string, value uint64) Field {
+	return Field{Key: key, kind: uintKind, num: int64(value)}
+}
+
+// Float64 returns a float64 field
+func Float64(key string, value float64) Field {
+	return Field{Key: key, kind: floatKind, num: int64(math.Float64bits(value))}
+}
+
+// Bool returns a bool field
+func Bool(key string, value bool) Field {
+	f := Field{Key: key, kind: boolKind}
+	if value {
+		f.num = 1
+	}
+	return f
+}
+
+// Dur returns a duration field, written in zerolog.DurationFieldUnit
+func Dur(key string, value time.Duration) Field {
+	return Field{Key: key, kind: durationKind, num: int64(value)}
+}
+
+// Time returns a time field, written in zerolog.TimeFieldFormat
+// Note: the monotonic clock reading is dropped
+func Time(key string, value time.Time) Field {
+	if value.IsZero() {
+		return Field{Key: key, kind: timeKind}
+	}
+	// the location pointer fits in the interface without allocating
+	return Field{Key: key, kind: timeKind, num: value.UnixNano(), iface: value.Location()}
+}
+
+// Any returns a field of any type, marshaled like the LogFields values
+func Any(key string, value any) Field {
+	return Field{Key: key, kind: anyKind, iface: value}
+}
+
+// Value returns the field value as it would be stored in LogFields
+func (f Field) Value() any {
+	switch f.kind {
+	case stringKind:
+		return f.str
+	case intKind:
+		return f.num
+	case uintKind:
+		return uint64(f.num)
+	case floatKind:
+		return math.Float64frombits(uint64(f.num))
+	case boolKind:
+		return f.num == 1
+	case durationKind:
+		return time.Duration(f.num)
+	case timeKind:
+		return f.time()
+	default:
+		return f.iface
+	}
+}
+
+func (f Field) time() time.Time {
+	loc, ok := f.iface.(*time.Location)
+	if !ok {
+		return time.Time{}
+	}
+	return time.Unix(0, f.num).In(loc)
+}
+
+func (f Field) appendTo(e *zerolog.Event) *zerolog.Event {
+	switch f.kind {
+	case stringKind:
+		return e.Str(f.Key, f.str)
+	case intKind:
+		return e.Int64(f.Key, f.num)
+	case uintKind:
+		return e.Uint64(f.Key, uint64(f.num))
+	case floatKind:
+		return e.Float64(f.Key, math.Float64frombits(uint64(f.num)))
+	case boolKind:
+		return e.Bool(f.Key, f.num == 1)
+	case durationKind:
+		return e.Dur(f.Key, time.Duration(f.num))
+	case timeKind:
+		return e.Time(f.Key, f.time())
+	default:
+		return e.Interface(f.Key, f.iface)
+	}
+}
+
+// fieldsToMap converts the typed fields for the map based pipeline, the last key wins
+func fieldsToMap(fields []Field) LogFields {
+	if len(fields) == 0 {
+		return nil
+	}
+	m := make(LogFields, len(fields))
+	for _, f := range fields {
+		m[f.Key] = f.Value()
+	}
+	return m
+}
+
+// TypedLogger logs typed fields without allocating (see Field), it's
+// obtained from Logger.Typed and shares the logger configuration.
+// The calls go through a concrete type so the variadic fields can stay on the stack.
+type TypedLogger struct {
+	l *logCfg
+}
+
+func (l *logCfg) Typed() *TypedLogger {
+	return l.typed
+}
+
+func (t *TypedLogger) Trace(msg string, fields ...Field) {
+	t.l.logFields(nil, msg, TraceLevel, nil, 2, fields)
+}
+
+func (t *TypedLogger) Debug(msg string, fields ...Field) {
+	t.l.logFields(nil, msg, DebugLevel, nil, 2, fields)
+}
+
+func (t *TypedLogger) Info(msg string, fields ...Field) {
+	t.l.logFields(nil, msg, InfoLevel, nil, 2, fields)
+}
+
+func (t *TypedLogger) Warn(msg string, fields ...Field) {
+	t.l.logFields(nil, msg, WarnLevel, nil, 2, fields)
+}
+
+func (t *TypedLogger) Error(msg string, err error, fields ...Field) {
+	t.l.logFields(nil, msg, ErrorLevel, err, 2, fields)
+}
+
+func (t *TypedLogger) Fatal(msg string, err error, fields ...Field) {
+	t.l.logFields(nil, msg, FatalLevel, err, 2, fields)
+}
+
+func (t *TypedLogger) Panic(msg string, err error, fields ...Field) {
+	t.l.logFields(nil, msg, PanicLevel, err, 2, fields)
+}
+
+// Log writes an event at any level, the context fields are extracted as in Logger.EventCtx
+func (t *TypedLogger) Log(ctx context.Context, level LogLevel, msg string, err error, fields ...Field) {
+	t.l.logFields(ctx, msg, level, err, 2, fields)
+}
+
+// logFields writes the typed fields straight to zerolog when no feature needs
+// them as a map (errors, context fields, redaction, hooks or deduplication),
+// otherwise it falls back to the map based pipeline
+func (l *logCfg) logFields(ctx context.Context, msg string, level LogLevel, err error, csfCount int, fields []Field) {
+	switch {
+	case l.shouldSkip(level):
+	case err != nil || !l.isFastPathEnabled(ctx):
+		var mf []LogFields
+		if m := fieldsToMap(fields); m != nil {
+			mf = []LogFields{m}
+		}
+		l.write(ctx, msg, level, err, csfCount+1, mf)
+	default:
+		l.fastWrite(msg, level, csfCount+1, fields)
+	}
+	l.terminate(msg, level)
+}
+
+func (l *logCfg) isFastPathEnabled(ctx context.Context) bool {
+	l.sc.mu.RLock()
+	enabled := len(l.sc.hooks) == 0 && !l.sc.redactor.isEnabled() && l.sc.deduper == nil
+	extractors := l.sc.extractors
+	l.sc.mu.RUnlock()
+	return enabled && len(extractContextFields(ctx, extractors)) == 0
+}
+
+func (l *logCfg) fastWrite(msg string, level LogLevel, csfCount int, fields []Field) {
+	logger, uc := l.snapshot()
+
+	event := logger.WithLevel(logToZerologMap[level])
+	if event == nil {
+		return
+	}
+
+	for _, f := range fields {
+		event = f.appendTo(event)
+	}
+
+	if uc.minCallerLevel <= level {
+		if pc, file, line, ok := runtime.Caller(csfCount); ok {
+			event = event.Str(zerolog.CallerFieldName, zerolog.CallerMarshalFunc(pc, file, line))
+		}
+	}
+
+	event.Msg(msg)
+}
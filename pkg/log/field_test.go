@@ -0,0 +1,81 @@
+package glog_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	glog "github.com/omgolab/go-commons/pkg/log"
+	"github.com/rs/zerolog"
+)
+
+func TestLog_TypedFields(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l, _ := glog.New(glog.WithWriters(buf))
+	l.DisableTimestamp()
+
+	ts := time.Date(2026, 10, 17, 8, 30, 0, 0, time.UTC)
+	l.Typed().Info("typed",
+		glog.Str("s", "v"), glog.Int("i", -1), glog.Uint64("u", 2), glog.Float64("f", 1.5),
+		glog.Bool("b", true), glog.Dur("d", 2*time.Second), glog.Time("t", ts), glog.Any("a", []int{1}))
+
+	want := `{"level":"info","s":"v","i":-1,"u":2,"f":1.5,"b":true,"d":2000,"t":"2026-10-17T08:30:00Z","a":[1],"message":"typed"}` + "\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got %s want %s", got, want)
+	}
+}
+
+func TestLog_SlowPath(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l, _ := glog.New(glog.WithWriters(buf), glog.WithRedactedFields("password"))
+	l.DisableTimestamp().DisableStackTraceOnError()
+
+	l.Typed().Error("typed", errors.New("boom"), glog.Str("password", "secret"), glog.Int("n", 1))
+
+	want := `{"level":"error","n":1,"password":"[REDACTED]","error":"boom","caller":`
+	if got := buf.String(); !bytes.HasPrefix([]byte(got), []byte(want)) {
+		t.Errorf("got %s want prefix %s", got, want)
+	}
+}
+
+func TestLog_ZeroAllocs(t *testing.T) {
+	l, _ := glog.New(glog.WithWriters(io.Discard))
+	tl := l.Typed()
+
+	allocs := testing.AllocsPerRun(100, func() {
+		tl.Info("hello", glog.Str("k", "v"), glog.Int("n", 42), glog.Bool("ok", true))
+	})
+	if allocs != 0 {
+		t.Errorf("expected no allocations, got %v", allocs)
+	}
+}
+
+func BenchmarkLog_Typed(b *testing.B) {
+	l, _ := glog.New(glog.WithWriters(io.Discard))
+	tl := l.Typed()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tl.Info("hello", glog.Str("k", "v"), glog.Int("n", i), glog.Bool("ok", true))
+	}
+}
+
+func BenchmarkLog_Map(b *testing.B) {
+	l, _ := glog.New(glog.WithWriters(io.Discard))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.Info("hello", glog.LogFields{"k": "v", "n": i, "ok": true})
+	}
+}
+
+func BenchmarkLog_Zerolog(b *testing.B) {
+	l := zerolog.New(io.Discard).With().Timestamp().Logger()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.Info().Str("k", "v").Int("n", i).Bool("ok", true).Msg("hello")
+	}
+}
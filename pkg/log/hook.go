@@ -0,0 +1,54 @@
+package glog
+
+import "golang.org/x/exp/slices"
+
+// Entry is the event passed to the hooks before it's written
+type Entry struct {
+	Level     LogLevel
+	Message   string
+	Namespace string
+	Fields    LogFields
+	Err       error
+}
+
+// Hook is called before an event is written.
+// It may mutate the entry (e.g. add fields) and returns false to veto the event.
+type Hook interface {
+	// Levels returns the levels the hook fires for, all levels if empty
+	Levels() []LogLevel
+	Fire(entry *Entry) bool
+}
+
+// HookFunc is the hook body; it returns false to veto the event
+type HookFunc func(entry *Entry) bool
+
+type funcHook struct {
+	fn     HookFunc
+	levels []LogLevel
+}
+
+// NewHook returns a hook calling fn for the given levels, all levels if none
+func NewHook(fn HookFunc, levels ...LogLevel) Hook {
+	return &funcHook{fn: fn, levels: levels}
+}
+
+func (h *funcHook) Levels() []LogLevel {
+	return h.levels
+}
+
+func (h *funcHook) Fire(entry *Entry) bool {
+	return h.fn(entry)
+}
+
+// fireHooks runs the hooks scoped to the entry level in order and stops on the first veto
+func fireHooks(hooks []Hook, entry *Entry) bool {
+	for _, h := range hooks {
+		if levels := h.Levels(); len(levels) > 0 && !slices.Contains(levels, entry.Level) {
+			continue
+		}
+		if !h.Fire(entry) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,37 @@
+package glog_test
+
+import (
+	"errors"
+	"testing"
+
+	glog "github.com/omgolab/go-commons/pkg/log"
+	glogtest "github.com/omgolab/go-commons/pkg/log/test"
+)
+
+func TestAddHook(t *testing.T) {
+	l := glogtest.New(t)
+
+	errCount := 0
+	l.AddHook(glog.NewHook(func(e *glog.Entry) bool {
+		errCount++
+		return true
+	}, glog.ErrorLevel))
+	l.AddHook(glog.NewHook(func(e *glog.Entry) bool {
+		e.Fields["host"] = "node-1"
+		return true
+	}))
+	l.AddHook(glog.NewHook(func(e *glog.Entry) bool {
+		return e.Message != "noisy"
+	}, glog.DebugLevel))
+
+	l.Debug("noisy")
+	l.Debug("useful")
+	l.Error("failed", errors.New("boom"))
+
+	if errCount != 1 {
+		t.Errorf("expected the error hook to fire once, got %d", errCount)
+	}
+	l.AssertNotLogged(t, glog.DebugLevel, "noisy", nil)
+	l.AssertLogged(t, glog.DebugLevel, "useful", glog.LogFields{"host": "node-1"})
+	l.AssertLogged(t, glog.ErrorLevel, "failed", glog.LogFields{"host": "node-1"})
+}
@@ -0,0 +1,169 @@
+package glog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/rs/zerolog"
+)
+
+// JournaldSocket is the default systemd-journald native protocol socket
+const JournaldSocket = "/run/systemd/journal/socket"
+
+// JournaldWriter writes the events to systemd-journald using its native protocol,
+// the event fields are sent as upper cased journal fields (e.g. `user-id` as `USER_ID`).
+// The events too large for a datagram are passed as a memfd (linux only).
+type JournaldWriter struct {
+	identifier string
+	socketPath string
+
+	mu   sync.Mutex
+	conn *net.UnixConn
+}
+
+// NewJournaldWriter connects to the journald socket, JournaldSocket if empty
+func NewJournaldWriter(socketPath, identifier string) (*JournaldWriter, error) {
+	if socketPath == "" {
+		socketPath = JournaldSocket
+	}
+	if identifier == "" {
+		identifier = filepath.Base(os.Args[0])
+	}
+
+	w := &JournaldWriter{identifier: identifier, socketPath: socketPath}
+	if err := w.connect(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *JournaldWriter) connect() error {
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: w.socketPath, Net: "unixgram"})
+	if err != nil {
+		return err
+	}
+	w.conn = conn
+	return nil
+}
+
+func (w *JournaldWriter) Write(p []byte) (int, error) {
+	return w.WriteLevel(levelFromEvent(p), p)
+}
+
+// WriteLevel implements zerolog.LevelWriter
+func (w *JournaldWriter) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	var evt map[string]any
+	if err := json.Unmarshal(p, &evt); err != nil {
+		return 0, err
+	}
+
+	msg, _ := evt[zerolog.MessageFieldName].(string)
+	delete(evt, zerolog.MessageFieldName)
+	delete(evt, zerolog.LevelFieldName)
+
+	buf := &bytes.Buffer{}
+	appendJournalField(buf, "MESSAGE", msg)
+	appendJournalField(buf, "PRIORITY", fmt.Sprint(syslogSeverity(level)))
+	appendJournalField(buf, "SYSLOG_IDENTIFIER", w.identifier)
+
+	keys := make([]string, 0, len(evt))
+	for k := range evt {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		name := journalFieldName(k)
+		if name == "" {
+			continue
+		}
+		v, ok := evt[k].(string)
+		if !ok {
+			b, _ := json.Marshal(evt[k])
+			v = string(b)
+		}
+		appendJournalField(buf, name, v)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	// retry once after reconnecting in case journald was restarted
+	var err error
+	for i := 0; i < 2; i++ {
+		if w.conn == nil {
+			if err = w.connect(); err != nil {
+				return 0, err
+			}
+		}
+		if err = w.send(buf.Bytes()); err == nil {
+			return len(p), nil
+		}
+		_ = w.conn.Close()
+		w.conn = nil
+	}
+	return 0, err
+}
+
+// send writes the datagram, the ones too large for the socket are passed as a file descriptor
+func (w *JournaldWriter) send(b []byte) error {
+	_, err := w.conn.Write(b)
+	if errors.Is(err, syscall.EMSGSIZE) || errors.Is(err, syscall.ENOBUFS) {
+		return sendJournalFd(w.conn, b)
+	}
+	return err
+}
+
+func (w *JournaldWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
+
+// appendJournalField uses the binary safe encoding for the values with new lines
+func appendJournalField(buf *bytes.Buffer, name, value string) {
+	buf.WriteString(name)
+	if !strings.ContainsRune(value, '\n') {
+		buf.WriteByte('=')
+		buf.WriteString(value)
+		buf.WriteByte('\n')
+		return
+	}
+
+	buf.WriteByte('\n')
+	_ = binary.Write(buf, binary.LittleEndian, uint64(len(value)))
+	buf.WriteString(value)
+	buf.WriteByte('\n')
+}
+
+// journalFieldName converts a field name to the journal's [A-Z0-9_] names
+// not starting with an underscore (reserved for trusted fields)
+func journalFieldName(name string) string {
+	b := make([]byte, 0, len(name))
+	for _, c := range []byte(strings.ToUpper(name)) {
+		if (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') {
+			b = append(b, c)
+		} else {
+			b = append(b, '_')
+		}
+	}
+	s := strings.TrimLeft(string(b), "_")
+	if s != "" && s[0] >= '0' && s[0] <= '9' {
+		s = "F_" + s
+	}
+	return s
+}
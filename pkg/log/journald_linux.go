@@ -0,0 +1,42 @@
+package glog
+
+import (
+	"net"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// sendJournalFd passes the event as a sealed memfd, journald reads the fields from it
+// (see https://systemd.io/JOURNAL_NATIVE_PROTOCOL/)
+func sendJournalFd(conn *net.UnixConn, b []byte) error {
+	fd, err := unix.MemfdCreate("glog-journal", unix.MFD_CLOEXEC|unix.MFD_ALLOW_SEALING)
+	if err != nil {
+		return err
+	}
+	f := os.NewFile(uintptr(fd), "glog-journal")
+	defer f.Close()
+
+	if _, err := f.Write(b); err != nil {
+		return err
+	}
+	seals := unix.F_SEAL_SHRINK | unix.F_SEAL_GROW | unix.F_SEAL_WRITE | unix.F_SEAL_SEAL
+	if _, err := unix.FcntlInt(f.Fd(), unix.F_ADD_SEALS, seals); err != nil {
+		return err
+	}
+
+	// WriteMsgUnix refuses the connected sockets, the rights are sent on the raw socket
+	rc, err := conn.SyscallConn()
+	if err != nil {
+		return err
+	}
+	rights := unix.UnixRights(int(f.Fd()))
+	var serr error
+	if err := rc.Write(func(s uintptr) bool {
+		serr = unix.Sendmsg(int(s), nil, rights, nil, 0)
+		return serr != unix.EAGAIN
+	}); err != nil {
+		return err
+	}
+	return serr
+}
@@ -0,0 +1,76 @@
+package glog_test
+
+import (
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	glog "github.com/omgolab/go-commons/pkg/log"
+)
+
+func TestJournaldWriter_LargeEventAndReconnect(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.sock")
+	listen := func() *net.UnixConn {
+		conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return conn
+	}
+	conn := listen()
+
+	w, err := glog.NewJournaldWriter(path, "myapp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	// too large for a datagram, the event is passed as a memfd
+	big := strings.Repeat("x", 1<<20)
+	if _, err := w.Write([]byte(`{"level":"info","message":"` + big + `"}`)); err != nil {
+		t.Fatal(err)
+	}
+	buf, oob := make([]byte, 4096), make([]byte, 64)
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, oobn, _, _, err := conn.ReadMsgUnix(buf, oob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 0 {
+		t.Fatalf("expected an empty datagram, got %d bytes", n)
+	}
+	msgs, err := syscall.ParseSocketControlMessage(oob[:oobn])
+	if err != nil || len(msgs) != 1 {
+		t.Fatalf("expected a control message: %v", err)
+	}
+	fds, err := syscall.ParseUnixRights(&msgs[0])
+	if err != nil || len(fds) != 1 {
+		t.Fatalf("expected a file descriptor: %v", err)
+	}
+	f := os.NewFile(uintptr(fds[0]), "memfd")
+	// the offset is shared with the writer, journald maps the file
+	got, err := io.ReadAll(io.NewSectionReader(f, 0, 2<<20))
+	f.Close()
+	if err != nil || !strings.Contains(string(got), "MESSAGE="+big+"\n") {
+		t.Errorf("unexpected memfd content (%d bytes, %v)", len(got), err)
+	}
+
+	// journald restarted, the next write reconnects
+	conn.Close()
+	_ = os.Remove(path)
+	conn = listen()
+	defer conn.Close()
+	if _, err := w.Write([]byte(`{"level":"info","message":"back"}`)); err != nil {
+		t.Fatal(err)
+	}
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, err = conn.Read(buf)
+	if err != nil || !strings.Contains(string(buf[:n]), "MESSAGE=back\n") {
+		t.Errorf("expected the event after reconnecting, got %q (%v)", buf[:n], err)
+	}
+}
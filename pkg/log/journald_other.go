@@ -0,0 +1,13 @@
+//go:build !linux
+
+package glog
+
+import (
+	"errors"
+	"net"
+)
+
+// sendJournalFd is only supported on linux, like journald
+func sendJournalFd(*net.UnixConn, []byte) error {
+	return errors.ErrUnsupported
+}
@@ -0,0 +1,59 @@
+package glog
+
+import (
+	"fmt"
+	"strings"
+)
+
+// String returns the lower case level name as rendered by zerolog
+func (ll LogLevel) String() string {
+	if zl, ok := logToZerologMap[ll]; ok {
+		return zl.String()
+	}
+	return fmt.Sprintf("LogLevel(%d)", int(ll))
+}
+
+// ParseLogLevel converts a level name (e.g. "warn") to a LogLevel
+func ParseLogLevel(s string) (LogLevel, error) {
+	name := strings.ToLower(strings.TrimSpace(s))
+	if name == "" {
+		return NoLevel, fmt.Errorf("glog: empty log level")
+	}
+	for ll, zl := range logToZerologMap {
+		if zl.String() == name {
+			return ll, nil
+		}
+	}
+	if name == "warning" {
+		return WarnLevel, nil
+	}
+	return NoLevel, fmt.Errorf("glog: unknown log level %q", s)
+}
+
+// ParseNamespaceLevels parses a spec like "db=warn,http=debug" into namespace levels.
+// An entry without a namespace (e.g. "info") is returned under the "" key and applies globally.
+func ParseNamespaceLevels(spec string) (map[string]LogLevel, error) {
+	levels := map[string]LogLevel{}
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		ns, name, found := strings.Cut(entry, "=")
+		if !found {
+			ns, name = "", entry
+		}
+		ns = strings.TrimSpace(ns)
+		if found && ns == "" {
+			return nil, fmt.Errorf("glog: missing namespace in %q", entry)
+		}
+
+		level, err := ParseLogLevel(name)
+		if err != nil {
+			return nil, err
+		}
+		levels[ns] = level
+	}
+	return levels, nil
+}
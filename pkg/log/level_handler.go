@@ -0,0 +1,187 @@
+package glog
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+)
+
+type levelPayload struct {
+	Level      string            `json:"level,omitempty"`
+	Namespaces map[string]string `json:"namespaces,omitempty"`
+}
+
+type levelHandler struct {
+	l Logger
+}
+
+// NewLevelHandler returns an http.Handler reporting (GET) and changing (PUT/POST) the
+// global and per-namespace minimum log levels at runtime. The new level is read from the
+// `level` and `ns` query parameters or from a JSON body like
+// {"level":"warn","namespaces":{"db":"error"}}; DELETE with `ns` removes a namespace level.
+func NewLevelHandler(l Logger) http.Handler {
+	return &levelHandler{l: l}
+}
+
+func (h *levelHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut, http.MethodPost:
+		p := levelPayload{Level: q.Get("level")}
+		if ns := q.Get("ns"); ns != "" {
+			p = levelPayload{Namespaces: map[string]string{ns: p.Level}}
+		}
+		if p.Level == "" && len(p.Namespaces) == 0 {
+			if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+				http.Error(w, "glog: invalid level payload: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+
+		if err := h.apply(p); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	case http.MethodDelete:
+		ns := q.Get("ns")
+		if ns == "" {
+			http.Error(w, "glog: missing ns parameter", http.StatusBadRequest)
+			return
+		}
+		h.l.RemoveNamespaceLogLevel(ns)
+	default:
+		w.Header().Set("Allow", "GET, PUT, POST, DELETE")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	p := levelPayload{Level: h.l.GetMinGlobalLogLevel().String()}
+	if nsLevels := h.l.GetNamespaceLogLevels(); len(nsLevels) > 0 {
+		p.Namespaces = make(map[string]string, len(nsLevels))
+		for ns, level := range nsLevels {
+			p.Namespaces[ns] = level.String()
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(p)
+}
+
+// apply validates all the levels first so a bad payload changes nothing
+func (h *levelHandler) apply(p levelPayload) error {
+	if p.Level == "" && len(p.Namespaces) == 0 {
+		return errors.New("glog: no level provided")
+	}
+
+	var global LogLevel
+	var err error
+	if p.Level != "" {
+		if global, err = ParseLogLevel(p.Level); err != nil {
+			return err
+		}
+	}
+
+	nsLevels := make(map[string]LogLevel, len(p.Namespaces))
+	for ns, name := range p.Namespaces {
+		if ns == "" {
+			return errors.New("glog: empty namespace")
+		}
+		if nsLevels[ns], err = ParseLogLevel(name); err != nil {
+			return err
+		}
+	}
+
+	if p.Level != "" {
+		h.l.SetMinGlobalLogLevel(global)
+	}
+	for ns, level := range nsLevels {
+		h.l.SetNamespaceLogLevel(ns, level)
+	}
+	return nil
+}
+
+// ToggleDebugOnSignal switches the global level to debug when one of the signals
+// (e.g. syscall.SIGUSR1) is received and reverts it after the timeout or on the next signal.
+// The returned function stops listening and restores the previous level if still toggled.
+// Without signals it listens to SIGUSR1, and to nothing on the platforms without it (e.g. windows),
+// as an empty list would relay every signal (e.g. SIGINT) to the toggle.
+func ToggleDebugOnSignal(l Logger, timeout time.Duration, sigs ...os.Signal) (stop func()) {
+	if len(sigs) == 0 {
+		sigs = defaultToggleSignals
+	}
+	if len(sigs) == 0 {
+		return func() {}
+	}
+
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, sigs...)
+	done := make(chan struct{})
+
+	var (
+		mu       sync.Mutex
+		timer    *time.Timer
+		prev     LogLevel
+		toggled  bool
+		gen      uint64
+		stopOnce sync.Once
+	)
+
+	// revertLocked restores the previous level
+	revertLocked := func() {
+		if !toggled {
+			return
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		toggled = false
+		l.SetMinGlobalLogLevel(prev)
+	}
+
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-ch:
+				mu.Lock()
+				if toggled {
+					revertLocked()
+					mu.Unlock()
+					continue
+				}
+				toggled = true
+				prev = l.GetMinGlobalLogLevel()
+				l.SetMinGlobalLogLevel(DebugLevel)
+				if timeout > 0 {
+					// a timer firing while the level is toggled again must not revert the new toggle
+					gen++
+					g := gen
+					timer = time.AfterFunc(timeout, func() {
+						mu.Lock()
+						defer mu.Unlock()
+						if g == gen {
+							revertLocked()
+						}
+					})
+				}
+				mu.Unlock()
+			}
+		}
+	}()
+
+	return func() {
+		stopOnce.Do(func() {
+			signal.Stop(ch)
+			close(done)
+			mu.Lock()
+			defer mu.Unlock()
+			revertLocked()
+		})
+	}
+}
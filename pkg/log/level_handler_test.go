@@ -0,0 +1,51 @@
+package glog_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	glog "github.com/omgolab/go-commons/pkg/log"
+)
+
+func TestLevelHandler(t *testing.T) {
+	l, _ := glog.New()
+	h := glog.NewLevelHandler(l)
+
+	tests := []struct {
+		name     string
+		method   string
+		target   string
+		body     string
+		wantCode int
+		wantBody string
+	}{
+		{"reports the current level", http.MethodGet, "/", "", http.StatusOK, `{"level":"debug"}`},
+		{"changes the level from the query", http.MethodPut, "/?level=warn", "", http.StatusOK, `{"level":"warn"}`},
+		{"changes the level from the body", http.MethodPost, "/", `{"level":"ERROR"}`, http.StatusOK, `{"level":"error"}`},
+		{"rejects unknown levels", http.MethodPut, "/?level=loud", "", http.StatusBadRequest, "unknown log level"},
+		{"changes a namespace level from the query", http.MethodPut, "/?ns=db&level=info", "", http.StatusOK, `"namespaces":{"db":"info"}`},
+		{"changes namespace levels from the body", http.MethodPost, "/", `{"namespaces":{"http":"trace"}}`, http.StatusOK, `"http":"trace"`},
+		{"rejects a bad namespace level atomically", http.MethodPost, "/", `{"level":"info","namespaces":{"db":"x"}}`, http.StatusBadRequest, "unknown log level"},
+		{"removes a namespace level", http.MethodDelete, "/?ns=http", "", http.StatusOK, `"namespaces":{"db":"info"}`},
+		{"rejects other methods", http.MethodPatch, "/", "", http.StatusMethodNotAllowed, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body)))
+			if rec.Code != tt.wantCode {
+				t.Errorf("expected status %d, got %d", tt.wantCode, rec.Code)
+			}
+			if !strings.Contains(rec.Body.String(), tt.wantBody) {
+				t.Errorf("expected body to contain %q, got %q", tt.wantBody, rec.Body.String())
+			}
+		})
+	}
+
+	if got := l.GetMinGlobalLogLevel(); got != glog.ErrorLevel {
+		t.Errorf("expected the global level to be %v, got %v", glog.ErrorLevel, got)
+	}
+}
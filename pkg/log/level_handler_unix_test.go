@@ -0,0 +1,61 @@
+//go:build unix
+
+package glog_test
+
+import (
+	"syscall"
+	"testing"
+	"time"
+
+	glog "github.com/omgolab/go-commons/pkg/log"
+)
+
+func TestToggleDebugOnSignal(t *testing.T) {
+	l, _ := glog.New()
+	l.SetMinGlobalLogLevel(glog.WarnLevel)
+
+	// SIGUSR1 by default
+	stop := glog.ToggleDebugOnSignal(l, 100*time.Millisecond)
+	defer stop()
+
+	signal := func() {
+		if err := syscall.Kill(syscall.Getpid(), syscall.SIGUSR1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	waitLevel := func(want glog.LogLevel) {
+		t.Helper()
+		deadline := time.Now().Add(time.Second)
+		for l.GetMinGlobalLogLevel() != want {
+			if time.Now().After(deadline) {
+				t.Fatalf("expected the level %s, got %s", want, l.GetMinGlobalLogLevel())
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	// the next signal reverts the toggle
+	signal()
+	waitLevel(glog.DebugLevel)
+	signal()
+	waitLevel(glog.WarnLevel)
+
+	// the timeout reverts the last of the repeated toggles
+	for i := 0; i < 5; i++ {
+		signal()
+		waitLevel(glog.DebugLevel)
+		signal()
+		waitLevel(glog.WarnLevel)
+	}
+	signal()
+	waitLevel(glog.DebugLevel)
+	waitLevel(glog.WarnLevel)
+
+	// stop restores the level while toggled
+	signal()
+	waitLevel(glog.DebugLevel)
+	stop()
+	if got := l.GetMinGlobalLogLevel(); got != glog.WarnLevel {
+		t.Errorf("expected stop to restore the level, got %s", got)
+	}
+}
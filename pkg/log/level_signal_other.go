@@ -0,0 +1,8 @@
+//go:build !unix
+
+package glog
+
+import "os"
+
+// defaultToggleSignals is empty as there is no user signal on these platforms
+var defaultToggleSignals []os.Signal
@@ -0,0 +1,11 @@
+//go:build unix
+
+package glog
+
+import (
+	"os"
+	"syscall"
+)
+
+// defaultToggleSignals are the signals of ToggleDebugOnSignal when none is given
+var defaultToggleSignals = []os.Signal{syscall.SIGUSR1}
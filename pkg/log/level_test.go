@@ -0,0 +1,66 @@
+package glog_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	glog "github.com/omgolab/go-commons/pkg/log"
+)
+
+func TestParseNamespaceLevels(t *testing.T) {
+	got, err := glog.ParseNamespaceLevels("info, db=warn,http=debug,")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]glog.LogLevel{"": glog.InfoLevel, "db": glog.WarnLevel, "http": glog.DebugLevel}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for ns, level := range want {
+		if got[ns] != level {
+			t.Errorf("namespace %q: expected %v, got %v", ns, level, got[ns])
+		}
+	}
+
+	for _, spec := range []string{"db=loud", "=warn"} {
+		if _, err := glog.ParseNamespaceLevels(spec); err == nil {
+			t.Errorf("expected an error for %q", spec)
+		}
+	}
+}
+
+func TestNamespaceLevels_HierarchicalMatching(t *testing.T) {
+	t.Setenv(glog.NamespaceLevelsEnvKey, "warn,db=error,db.gc=debug")
+
+	buf := &bytes.Buffer{}
+	base, err := glog.New(glog.WithMultiLogger(buf), glog.WithNamespaceLevelsFromEnv())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logAll := func(ns string) {
+		l, _ := glog.New(glog.WithMultiLogger(buf), glog.WithNamespaceLevelsFromEnv())
+		l.SetContextNS(ns)
+		l.Debug(ns + "-debug")
+		l.Warn(ns + "-warn")
+		l.Error(ns+"-error", nil)
+	}
+	logAll("db")
+	logAll("db.gc")
+	logAll("db.gcx")
+	logAll("http")
+	base.Info("root-info")
+
+	out := buf.String()
+	for _, want := range []string{"db-error", "db.gc-debug", "db.gc-warn", "db.gcx-error", "http-warn"} {
+		if !strings.Contains(out, `"`+want+`"`) {
+			t.Errorf("expected %q to be logged", want)
+		}
+	}
+	for _, unwanted := range []string{"db-warn", "db.gcx-warn", "http-debug", "root-info"} {
+		if strings.Contains(out, `"`+unwanted+`"`) {
+			t.Errorf("expected %q to be skipped", unwanted)
+		}
+	}
+}
@@ -1,15 +1,21 @@
 package glog
 
 import (
+	"context"
 	"fmt"
 	"io"
+	"os"
+	"runtime"
+	"strings"
 	"sync"
+	"time"
 
 	gcollections "github.com/omgolab/go-commons/pkg/collections"
 	"github.com/rs/zerolog"
-	"github.com/rs/zerolog/pkgerrors"
 )
 
+const defaultConsoleTimeFormat = "Mon 02-Jan-06 03:04:05 PM -0700"
+
 type LogFields map[string]any
 type LogLevel int
 type LogStr string
@@ -36,12 +42,11 @@ var (
 		FatalLevel: zerolog.FatalLevel,
 		PanicLevel: zerolog.PanicLevel,
 	}
-
-	globalInitOnce sync.Once
 )
 
 type Logger interface {
 	Event(msg string, level LogLevel, err error, csfCount int, fields ...LogFields)
+	EventCtx(ctx context.Context, msg string, level LogLevel, err error, csfCount int, fields ...LogFields)
 	Trace(msg string, fields ...LogFields)
 	Debug(msg string, fields ...LogFields)
 	Info(msg string, fields ...LogFields)
@@ -49,11 +54,27 @@ type Logger interface {
 	Error(msg string, err error, fields ...LogFields)
 	Fatal(msg string, err error, fields ...LogFields)
 	Panic(msg string, err error, fields ...LogFields)
+	TraceCtx(ctx context.Context, msg string, fields ...LogFields)
+	DebugCtx(ctx context.Context, msg string, fields ...LogFields)
+	InfoCtx(ctx context.Context, msg string, fields ...LogFields)
+	WarnCtx(ctx context.Context, msg string, fields ...LogFields)
+	ErrorCtx(ctx context.Context, msg string, err error, fields ...LogFields)
+	FatalCtx(ctx context.Context, msg string, err error, fields ...LogFields)
+	PanicCtx(ctx context.Context, msg string, err error, fields ...LogFields)
+	Typed() *TypedLogger
 	Println(msg ...any)
 	Printf(format string, v ...any)
+	IsEnabled(level LogLevel) bool
+	GetMinGlobalLogLevel() LogLevel
 	SetMinGlobalLogLevel(minLevel LogLevel) Logger
+	GetNamespaceLogLevels() map[string]LogLevel
+	SetNamespaceLogLevel(ns string, minLevel LogLevel) Logger
+	RemoveNamespaceLogLevel(ns string) Logger
 	SetMinCallerAttachLevel(minLevel LogLevel) Logger
 	SetContextNS(keyword string) Logger
+	AddHook(h Hook) Logger
+	AddShutdownHook(h ShutdownHook) Logger
+	Flush() error
 	DisableStackTraceOnError() Logger
 	DisableTimestamp() Logger
 	DisableAllLoggers() Logger
@@ -66,6 +87,17 @@ type sharedCfg struct {
 	isDisabled  bool
 	writers     []io.Writer
 	timeFormat  string
+	extractors  []ContextExtractor
+	nsLevels    map[string]LogLevel
+	redactor    *redactor
+	hooks       []Hook
+	deduper     *deduper
+
+	consoleExcluded []string
+
+	shutdownHooks   []ShutdownHook
+	shutdownTimeout time.Duration
+	exitFn          func(code int)
 }
 
 type uniqueCfg struct {
@@ -78,33 +110,37 @@ type uniqueCfg struct {
 type logCfg struct {
 	sc *sharedCfg
 
-	mu sync.RWMutex
-	zl zerolog.Logger
-	uc uniqueCfg
+	mu    sync.RWMutex
+	zl    zerolog.Logger
+	uc    uniqueCfg
+	typed *TypedLogger
 }
 
 func New(options ...LogOption) (Logger, error) {
-	globalInitOnce.Do(func() {
-		zerolog.ErrorStackMarshaler = pkgerrors.MarshalStack
-	})
-
-	defaultWriter := newConsoleWriter("Mon 02-Jan-06 03:04:05 PM -0700")
+	defaultWriter := newConsoleWriter(defaultConsoleTimeFormat)
 	l := &logCfg{
 		sc: &sharedCfg{
 			minLogLevel: DebugLevel,
 			writers:     []io.Writer{defaultWriter},
 			timeFormat:  defaultWriter.TimeFormat,
+			extractors:  []ContextExtractor{FieldsExtractor, TraceExtractor, RequestIDExtractor},
+
+			shutdownTimeout: defaultShutdownTimeout,
+			exitFn:          os.Exit,
 		},
 		uc: uniqueCfg{
 			minCallerLevel: WarnLevel,
 		},
 	}
 
+	l.typed = &TypedLogger{l: l}
+
 	for _, opt := range options {
 		if err := opt(l); err != nil {
 			return nil, err
 		}
 	}
+	l.excludeConsoleFields()
 
 	if err := l.rebuildLogger(); err != nil {
 		return nil, err
@@ -116,9 +152,26 @@ func New(options ...LogOption) (Logger, error) {
 func newConsoleWriter(format string) *zerolog.ConsoleWriter {
 	cw := zerolog.NewConsoleWriter()
 	cw.TimeFormat = format
+	cw.FieldsExclude = []string{ErrorChainFieldName, zerolog.ErrorStackFieldName}
+	cw.FormatExtra = formatErrorDetails
 	return &cw
 }
 
+// excludeConsoleFields hides the fields set by WithConsoleFieldsExcluded from the console writers
+func (l *logCfg) excludeConsoleFields() {
+	if len(l.sc.consoleExcluded) == 0 {
+		return
+	}
+	for _, w := range l.sc.writers {
+		switch cw := w.(type) {
+		case *zerolog.ConsoleWriter:
+			cw.FieldsExclude = append(cw.FieldsExclude[:len(cw.FieldsExclude):len(cw.FieldsExclude)], l.sc.consoleExcluded...)
+		case *PrettyWriter:
+			cw.excluded = append(cw.excluded[:len(cw.excluded):len(cw.excluded)], l.sc.consoleExcluded...)
+		}
+	}
+}
+
 func (l *logCfg) rebuildLogger() error {
 	l.mu.Lock()
 	defer l.mu.Unlock()
@@ -139,9 +192,6 @@ func (l *logCfg) rebuildLoggerLocked() error {
 	if !l.uc.isTimestampOff {
 		ctx = ctx.Timestamp()
 	}
-	if !l.uc.isStackTraceOff {
-		ctx = ctx.Stack()
-	}
 
 	l.zl = ctx.Logger()
 	return nil
@@ -164,12 +214,39 @@ func (l *logCfg) snapshot() (zerolog.Logger, uniqueCfg) {
 }
 
 func (l *logCfg) shouldSkip(level LogLevel) bool {
+	l.mu.RLock()
+	ns := l.uc.ns
+	l.mu.RUnlock()
+
 	l.sc.mu.RLock()
-	disabled := l.sc.isDisabled || level < l.sc.minLogLevel
+	disabled := l.sc.isDisabled || level < l.sc.minLevelForLocked(ns)
 	l.sc.mu.RUnlock()
 	return disabled
 }
 
+// minLevelForLocked returns the level of the most specific namespace matching ns
+// (e.g. "db" matches "db.gc") or the global level if none matches
+func (sc *sharedCfg) minLevelForLocked(ns string) LogLevel {
+	if ns == "" || len(sc.nsLevels) == 0 {
+		return sc.minLogLevel
+	}
+
+	for {
+		if level, ok := sc.nsLevels[ns]; ok {
+			return level
+		}
+		i := strings.LastIndexByte(ns, '.')
+		if i < 0 {
+			return sc.minLogLevel
+		}
+		ns = ns[:i]
+	}
+}
+
+func (l *logCfg) IsEnabled(level LogLevel) bool {
+	return !l.shouldSkip(level)
+}
+
 func (l *logCfg) update(nuc uniqueCfg) Logger {
 	l.mu.Lock()
 	l.uc = nuc
@@ -178,6 +255,12 @@ func (l *logCfg) update(nuc uniqueCfg) Logger {
 	return l
 }
 
+func (l *logCfg) GetMinGlobalLogLevel() LogLevel {
+	l.sc.mu.RLock()
+	defer l.sc.mu.RUnlock()
+	return l.sc.minLogLevel
+}
+
 func (l *logCfg) SetMinGlobalLogLevel(minLevel LogLevel) Logger {
 	l.sc.mu.Lock()
 	l.sc.minLogLevel = minLevel
@@ -185,6 +268,33 @@ func (l *logCfg) SetMinGlobalLogLevel(minLevel LogLevel) Logger {
 	return l
 }
 
+func (l *logCfg) GetNamespaceLogLevels() map[string]LogLevel {
+	l.sc.mu.RLock()
+	defer l.sc.mu.RUnlock()
+	levels := make(map[string]LogLevel, len(l.sc.nsLevels))
+	for ns, level := range l.sc.nsLevels {
+		levels[ns] = level
+	}
+	return levels
+}
+
+func (l *logCfg) SetNamespaceLogLevel(ns string, minLevel LogLevel) Logger {
+	l.sc.mu.Lock()
+	if l.sc.nsLevels == nil {
+		l.sc.nsLevels = map[string]LogLevel{}
+	}
+	l.sc.nsLevels[ns] = minLevel
+	l.sc.mu.Unlock()
+	return l
+}
+
+func (l *logCfg) RemoveNamespaceLogLevel(ns string) Logger {
+	l.sc.mu.Lock()
+	delete(l.sc.nsLevels, ns)
+	l.sc.mu.Unlock()
+	return l
+}
+
 func (l *logCfg) DisableStackTraceOnError() Logger {
 	nuc := l.uc
 	nuc.isStackTraceOff = true
@@ -209,6 +319,20 @@ func (l *logCfg) SetContextNS(keyword string) Logger {
 	return l.update(nuc)
 }
 
+func (l *logCfg) AddHook(h Hook) Logger {
+	l.sc.mu.Lock()
+	l.sc.hooks = append(l.sc.hooks[:len(l.sc.hooks):len(l.sc.hooks)], h)
+	l.sc.mu.Unlock()
+	return l
+}
+
+func (l *logCfg) AddShutdownHook(h ShutdownHook) Logger {
+	l.sc.mu.Lock()
+	l.sc.shutdownHooks = append(l.sc.shutdownHooks[:len(l.sc.shutdownHooks):len(l.sc.shutdownHooks)], h)
+	l.sc.mu.Unlock()
+	return l
+}
+
 func (l *logCfg) DisableAllLoggers() Logger {
 	l.sc.mu.Lock()
 	l.sc.isDisabled = true
@@ -217,28 +341,127 @@ func (l *logCfg) DisableAllLoggers() Logger {
 }
 
 func (l *logCfg) Event(msg string, level LogLevel, err error, csfCount int, fields ...LogFields) {
+	l.event(nil, msg, level, err, csfCount+1, fields)
+}
+
+func (l *logCfg) EventCtx(ctx context.Context, msg string, level LogLevel, err error, csfCount int, fields ...LogFields) {
+	l.event(ctx, msg, level, err, csfCount+1, fields)
+}
+
+// event writes the event then exits or panics for the Fatal and Panic levels,
+// even when the event itself is skipped or vetoed by a hook
+func (l *logCfg) event(ctx context.Context, msg string, level LogLevel, err error, csfCount int, fields []LogFields) {
+	l.write(ctx, msg, level, err, csfCount+1, fields)
+	l.terminate(msg, level)
+}
+
+func (l *logCfg) write(ctx context.Context, msg string, level LogLevel, err error, csfCount int, fields []LogFields) {
 	if l.shouldSkip(level) {
 		return
 	}
 
+	// context fields go first so the explicit fields can override them
+	if cf := extractContextFields(ctx, l.extractorsSnapshot()); len(cf) > 0 {
+		fields = append([]LogFields{cf}, fields...)
+	}
+
 	logger, uc := l.snapshot()
-	event := logger.WithLevel(logToZerologMap[level])
 
+	var merged LogFields
 	if len(fields) > 0 {
-		if merged := gcollections.MergeMaps(fields...); merged != nil {
-			event = event.Fields(merged)
+		merged = gcollections.MergeMaps(fields...)
+	}
+
+	// the hooks see the original error and their changes are redacted below
+	if hooks := l.hooksSnapshot(); len(hooks) > 0 {
+		if merged == nil {
+			merged = LogFields{}
 		}
+		entry := &Entry{Level: level, Message: msg, Namespace: uc.ns, Fields: merged, Err: err}
+		if !fireHooks(hooks, entry) {
+			return
+		}
+		msg, merged, err = entry.Message, entry.Fields, entry.Err
 	}
 
 	if err != nil {
-		event = event.Err(err)
+		chain := errorChain(err)
+		// the error fields go first so the explicit fields can override them
+		merged = gcollections.MergeMaps(chainFields(chain), merged)
+		if msgs := chainMessages(chain); len(msgs) > 1 {
+			merged[ErrorChainFieldName] = msgs
+		}
+		if !uc.isStackTraceOff {
+			merged[zerolog.ErrorStackFieldName] = errorStack(err, callers(csfCount+2))
+		}
 	}
 
+	if r := l.redactorSnapshot(); r.isEnabled() {
+		msg = r.redactString(msg)
+		err = r.redactErr(err)
+		merged = r.redactFields(merged)
+	}
+
+	// resolve the caller now as a deduplicated event is emitted later
+	var caller string
 	if uc.minCallerLevel <= level {
-		event = event.Caller(csfCount)
+		if c, ok := callerFromContext(ctx); ok {
+			caller = c
+		} else if pc, file, line, ok := runtime.Caller(csfCount); ok {
+			caller = zerolog.CallerMarshalFunc(pc, file, line)
+		}
 	}
 
-	event.Msg(msg)
+	emit := func(extra LogFields) {
+		event := logger.WithLevel(logToZerologMap[level])
+
+		if len(merged) > 0 {
+			event = event.Fields(map[string]any(merged))
+		}
+
+		if len(extra) > 0 {
+			event = event.Fields(map[string]any(extra))
+		}
+
+		if err != nil {
+			event = event.Err(err)
+		}
+
+		if caller != "" {
+			event = event.Str(zerolog.CallerFieldName, caller)
+		}
+
+		event.Msg(msg)
+	}
+
+	if d := l.deduperSnapshot(); d != nil && !d.observe(newDedupKey(level, uc.ns, msg, err, merged), emit) {
+		return
+	}
+	emit(nil)
+}
+
+func (l *logCfg) deduperSnapshot() *deduper {
+	l.sc.mu.RLock()
+	defer l.sc.mu.RUnlock()
+	return l.sc.deduper
+}
+
+func (l *logCfg) hooksSnapshot() []Hook {
+	l.sc.mu.RLock()
+	defer l.sc.mu.RUnlock()
+	return l.sc.hooks
+}
+
+func (l *logCfg) redactorSnapshot() *redactor {
+	l.sc.mu.RLock()
+	defer l.sc.mu.RUnlock()
+	return l.sc.redactor
+}
+
+func (l *logCfg) extractorsSnapshot() []ContextExtractor {
+	l.sc.mu.RLock()
+	defer l.sc.mu.RUnlock()
+	return l.sc.extractors
 }
 
 func (l *logCfg) Trace(msg string, fields ...LogFields) {
@@ -269,6 +492,34 @@ func (l *logCfg) Panic(msg string, err error, fields ...LogFields) {
 	l.Event(msg, PanicLevel, err, 2, fields...)
 }
 
+func (l *logCfg) TraceCtx(ctx context.Context, msg string, fields ...LogFields) {
+	l.EventCtx(ctx, msg, TraceLevel, nil, 2, fields...)
+}
+
+func (l *logCfg) DebugCtx(ctx context.Context, msg string, fields ...LogFields) {
+	l.EventCtx(ctx, msg, DebugLevel, nil, 2, fields...)
+}
+
+func (l *logCfg) InfoCtx(ctx context.Context, msg string, fields ...LogFields) {
+	l.EventCtx(ctx, msg, InfoLevel, nil, 2, fields...)
+}
+
+func (l *logCfg) WarnCtx(ctx context.Context, msg string, fields ...LogFields) {
+	l.EventCtx(ctx, msg, WarnLevel, nil, 2, fields...)
+}
+
+func (l *logCfg) ErrorCtx(ctx context.Context, msg string, err error, fields ...LogFields) {
+	l.EventCtx(ctx, msg, ErrorLevel, err, 2, fields...)
+}
+
+func (l *logCfg) FatalCtx(ctx context.Context, msg string, err error, fields ...LogFields) {
+	l.EventCtx(ctx, msg, FatalLevel, err, 2, fields...)
+}
+
+func (l *logCfg) PanicCtx(ctx context.Context, msg string, err error, fields ...LogFields) {
+	l.EventCtx(ctx, msg, PanicLevel, err, 2, fields...)
+}
+
 func (l *logCfg) Println(msg ...any) {
 	if l.shouldSkip(DebugLevel) {
 		return
@@ -1,15 +1,24 @@
 package glog
 
 import (
+	"fmt"
 	"io"
 	"os"
+	"path"
+	"regexp"
+	"strings"
+	"time"
 
+	genv "github.com/omgolab/go-commons/pkg/env"
 	gfile "github.com/omgolab/go-commons/pkg/file/open"
 	"github.com/rs/zerolog"
 )
 
 type LogOption func(*logCfg) error
 
+// NamespaceLevelsEnvKey is the default env variable read by WithNamespaceLevelsFromEnv
+const NamespaceLevelsEnvKey = "GLOG_LEVELS"
+
 // Option setters:
 // Note: we use these option when
 // - we need to replace the default logger
@@ -37,6 +46,14 @@ func WithJsonStdOut() LogOption {
 	}
 }
 
+// WithWriters replaces the default console writer (and any writer set before) with ws
+func WithWriters(ws ...io.Writer) LogOption {
+	return func(l *logCfg) error {
+		l.sc.writers = append([]io.Writer{}, ws...)
+		return nil
+	}
+}
+
 func WithMultiLogger(ws ...io.Writer) LogOption {
 	return func(l *logCfg) error {
 		l.sc.writers = append(l.sc.writers, ws...)
@@ -52,6 +69,9 @@ func WithTimestampFormat(format string) LogOption {
 				cw.TimeFormat = format
 				l.sc.writers[i] = cw
 			}
+			if pw, ok := w.(*PrettyWriter); ok {
+				pw.timeFormat = format
+			}
 		}
 		return nil
 	}
@@ -63,3 +83,208 @@ func WithDefaultLogLevel(level LogLevel) LogOption {
 		return nil
 	}
 }
+
+// WithContextExtractors adds extractors used by the context-aware methods
+// (e.g. to read OpenTelemetry span contexts) on top of the default ones
+func WithContextExtractors(extractors ...ContextExtractor) LogOption {
+	return func(l *logCfg) error {
+		l.sc.extractors = append(l.sc.extractors, extractors...)
+		return nil
+	}
+}
+
+// WithNamespaceLevels sets the minimum levels per context namespace (see SetContextNS).
+// A level applies to the namespace and all its dot separated children (`db` applies to `db.gc`).
+func WithNamespaceLevels(levels map[string]LogLevel) LogOption {
+	return func(l *logCfg) error {
+		if l.sc.nsLevels == nil {
+			l.sc.nsLevels = map[string]LogLevel{}
+		}
+		for ns, level := range levels {
+			if ns == "" {
+				l.sc.minLogLevel = level
+				continue
+			}
+			l.sc.nsLevels[ns] = level
+		}
+		return nil
+	}
+}
+
+// WithNamespaceLevelsFromEnv sets the namespace levels from an env variable spec
+// like `GLOG_LEVELS=db=warn,http=debug`; the key defaults to NamespaceLevelsEnvKey
+func WithNamespaceLevelsFromEnv(key ...string) LogOption {
+	return func(l *logCfg) error {
+		k := NamespaceLevelsEnvKey
+		if len(key) > 0 && key[0] != "" {
+			k = key[0]
+		}
+
+		spec := genv.Env(k, "")
+		if spec == "" {
+			return nil
+		}
+
+		levels, err := ParseNamespaceLevels(spec)
+		if err != nil {
+			return fmt.Errorf("glog: invalid %s: %w", k, err)
+		}
+		return WithNamespaceLevels(levels)(l)
+	}
+}
+
+// WithRedactedFields redacts the values of the fields whose names match any of the
+// case insensitive glob patterns (e.g. `password`, `*token*`, `authorization`)
+func WithRedactedFields(patterns ...string) LogOption {
+	return func(l *logCfg) error {
+		if l.sc.redactor == nil {
+			l.sc.redactor = &redactor{}
+		}
+		for _, p := range patterns {
+			p = strings.ToLower(p)
+			if _, err := path.Match(p, ""); err != nil {
+				return fmt.Errorf("glog: invalid redacted field pattern %q: %w", p, err)
+			}
+			l.sc.redactor.fieldPatterns = append(l.sc.redactor.fieldPatterns, p)
+		}
+		return nil
+	}
+}
+
+// WithRedactedValues replaces the parts of messages, errors and string field values
+// matching any of the patterns (e.g. CardNumberPattern, JWTPattern) with RedactedText
+func WithRedactedValues(patterns ...*regexp.Regexp) LogOption {
+	return func(l *logCfg) error {
+		if l.sc.redactor == nil {
+			l.sc.redactor = &redactor{}
+		}
+		for _, p := range patterns {
+			l.sc.redactor.valuePatterns = append(l.sc.redactor.valuePatterns, valuePattern{re: p})
+		}
+		return nil
+	}
+}
+
+// WithRedactedCardNumbers redacts the CardNumberPattern matches passing the Luhn check,
+// unlike WithRedactedValues(CardNumberPattern) which redacts every match
+func WithRedactedCardNumbers() LogOption {
+	return func(l *logCfg) error {
+		if l.sc.redactor == nil {
+			l.sc.redactor = &redactor{}
+		}
+		l.sc.redactor.valuePatterns = append(l.sc.redactor.valuePatterns, valuePattern{re: CardNumberPattern, luhn: true})
+		return nil
+	}
+}
+
+// WithSyslog adds a RFC 5424 syslog writer (see NewSyslogWriter)
+func WithSyslog(network, addr, appName string) LogOption {
+	return func(l *logCfg) error {
+		w, err := NewSyslogWriter(network, addr, appName)
+		if err != nil {
+			return err
+		}
+		l.sc.writers = append(l.sc.writers, w)
+		return nil
+	}
+}
+
+// WithJournald adds a systemd-journald native protocol writer (see NewJournaldWriter)
+func WithJournald(socketPath, identifier string) LogOption {
+	return func(l *logCfg) error {
+		w, err := NewJournaldWriter(socketPath, identifier)
+		if err != nil {
+			return err
+		}
+		l.sc.writers = append(l.sc.writers, w)
+		return nil
+	}
+}
+
+// WithShipper adds a writer shipping the JSON lines to a TCP or HTTP collector (see NewShipper);
+// use NewShipper with WithMultiLogger instead to keep a handle for closing it
+func WithShipper(endpoint string, opts ...ShipperOption) LogOption {
+	return func(l *logCfg) error {
+		s, err := NewShipper(endpoint, opts...)
+		if err != nil {
+			return err
+		}
+		l.sc.writers = append(l.sc.writers, s)
+		return nil
+	}
+}
+
+// WithRotatingFileLogger adds a file writer rotating the file over maxSize bytes (see NewRotatingFile)
+func WithRotatingFileLogger(filename string, maxSize int64, maxBackups int) LogOption {
+	return func(l *logCfg) error {
+		f, err := NewRotatingFile(filename, maxSize, maxBackups)
+		if err != nil {
+			return err
+		}
+		l.sc.writers = append(l.sc.writers, f)
+		return nil
+	}
+}
+
+// WithExitFunc replaces os.Exit called by Fatal, e.g. to keep tests running
+func WithExitFunc(fn func(code int)) LogOption {
+	return func(l *logCfg) error {
+		if fn == nil {
+			return fmt.Errorf("glog: exit func is nil")
+		}
+		l.sc.exitFn = fn
+		return nil
+	}
+}
+
+// WithShutdownTimeout sets the max time Fatal waits for the writers and shutdown hooks
+func WithShutdownTimeout(d time.Duration) LogOption {
+	return func(l *logCfg) error {
+		if d <= 0 {
+			return fmt.Errorf("glog: invalid shutdown timeout %s", d)
+		}
+		l.sc.shutdownTimeout = d
+		return nil
+	}
+}
+
+// WithDedup collapses the identical (level, message, error, fields) events logged within the window
+// into the first event and one more event carrying a `repeated=N` field.
+// Call Logger.Flush before exiting to write the held back duplicates.
+func WithDedup(window time.Duration) LogOption {
+	return func(l *logCfg) error {
+		if window <= 0 {
+			return fmt.Errorf("glog: invalid dedup window %s", window)
+		}
+		l.sc.deduper = &deduper{window: window}
+		return nil
+	}
+}
+
+// WithConsoleFieldsExcluded hides the fields from the console writers (default and pretty),
+// e.g. the routing fields of the filter loggers; the other writers still get them
+func WithConsoleFieldsExcluded(names ...string) LogOption {
+	return func(l *logCfg) error {
+		l.sc.consoleExcluded = append(l.sc.consoleExcluded, names...)
+		return nil
+	}
+}
+
+// WithPrettyConsole replaces the default console writer with a PrettyWriter
+func WithPrettyConsole(opts ...PrettyOption) LogOption {
+	return func(l *logCfg) error {
+		// keep a format set by WithTimestampFormat before this option
+		if l.sc.timeFormat != defaultConsoleTimeFormat {
+			opts = append([]PrettyOption{WithPrettyTimeFormat(l.sc.timeFormat)}, opts...)
+		}
+		pw := NewPrettyWriter(opts...)
+		for i, w := range l.sc.writers {
+			if _, ok := w.(*zerolog.ConsoleWriter); ok {
+				l.sc.writers[i] = pw
+				return nil
+			}
+		}
+		l.sc.writers = append(l.sc.writers, pw)
+		return nil
+	}
+}
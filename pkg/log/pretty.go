@@ -0,0 +1,320 @@
+package glog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mattn/go-isatty"
+	"github.com/rs/zerolog"
+)
+
+const (
+	defaultPrettyTimeFormat   = "15:04:05.000"
+	defaultPrettyMaxValueLen  = 256
+	defaultPrettyMessageWidth = 40
+	maxPrettyCallerWidth      = 40
+)
+
+const (
+	colorReset   = "\x1b[0m"
+	colorBold    = "\x1b[1m"
+	colorRed     = "\x1b[31m"
+	colorGreen   = "\x1b[32m"
+	colorYellow  = "\x1b[33m"
+	colorCyan    = "\x1b[36m"
+	colorGray    = "\x1b[90m"
+	colorBoldRed = "\x1b[1;31m"
+)
+
+var prettyLevels = map[string]struct{ name, color string }{
+	zerolog.TraceLevel.String(): {"TRC", colorGray},
+	zerolog.DebugLevel.String(): {"DBG", colorCyan},
+	zerolog.InfoLevel.String():  {"INF", colorGreen},
+	zerolog.WarnLevel.String():  {"WRN", colorYellow},
+	zerolog.ErrorLevel.String(): {"ERR", colorRed},
+	zerolog.FatalLevel.String(): {"FTL", colorBoldRed},
+	zerolog.PanicLevel.String(): {"PNC", colorBoldRed},
+}
+
+type PrettyOption func(*PrettyWriter)
+
+// PrettyWriter is a human friendly console writer with colored levels, aligned columns,
+// multi-line errors and stacks; the compact mode renders single lines without colors for CI logs
+type PrettyWriter struct {
+	out          io.Writer
+	isColor      bool
+	isCompact    bool
+	timeFormat   string
+	priority     []string
+	maxValueLen  int
+	messageWidth int
+	forcedColor  *bool
+	callerWidth  int
+	excluded     []string
+	mu           sync.Mutex
+}
+
+// WithPrettyOutput sets the destination, os.Stdout by default
+func WithPrettyOutput(w io.Writer) PrettyOption {
+	return func(pw *PrettyWriter) {
+		pw.out = w
+	}
+}
+
+// WithPrettyColor forces the colors on or off instead of detecting NO_COLOR and the TTY
+func WithPrettyColor(enabled bool) PrettyOption {
+	return func(pw *PrettyWriter) {
+		pw.forcedColor = &enabled
+	}
+}
+
+// WithPrettyCompact renders every event on a single line without colors or alignment
+func WithPrettyCompact() PrettyOption {
+	return func(pw *PrettyWriter) {
+		pw.isCompact = true
+	}
+}
+
+// WithPrettyFieldPriority renders the given fields first, in order, before the sorted others
+func WithPrettyFieldPriority(names ...string) PrettyOption {
+	return func(pw *PrettyWriter) {
+		pw.priority = append(pw.priority, names...)
+	}
+}
+
+// WithPrettyMaxValueLen truncates the field values longer than n runes, 0 disables it
+func WithPrettyMaxValueLen(n int) PrettyOption {
+	return func(pw *PrettyWriter) {
+		pw.maxValueLen = n
+	}
+}
+
+// WithPrettyMessageWidth pads the messages to n runes so the fields line up
+func WithPrettyMessageWidth(n int) PrettyOption {
+	return func(pw *PrettyWriter) {
+		pw.messageWidth = n
+	}
+}
+
+// WithPrettyTimeFormat sets the timestamp layout
+func WithPrettyTimeFormat(format string) PrettyOption {
+	return func(pw *PrettyWriter) {
+		pw.timeFormat = format
+	}
+}
+
+// NewPrettyWriter returns a pretty console writer
+func NewPrettyWriter(opts ...PrettyOption) *PrettyWriter {
+	pw := &PrettyWriter{
+		out:          os.Stdout,
+		timeFormat:   defaultPrettyTimeFormat,
+		maxValueLen:  defaultPrettyMaxValueLen,
+		messageWidth: defaultPrettyMessageWidth,
+	}
+	for _, opt := range opts {
+		opt(pw)
+	}
+
+	switch {
+	case pw.forcedColor != nil:
+		pw.isColor = *pw.forcedColor
+	case pw.isCompact || os.Getenv("NO_COLOR") != "":
+		pw.isColor = false
+	default:
+		f, ok := pw.out.(*os.File)
+		pw.isColor = ok && (isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd()))
+	}
+	return pw
+}
+
+func (pw *PrettyWriter) Write(p []byte) (int, error) {
+	var evt map[string]any
+	d := json.NewDecoder(bytes.NewReader(p))
+	d.UseNumber()
+	if err := d.Decode(&evt); err != nil {
+		return 0, fmt.Errorf("glog: cannot decode event: %w", err)
+	}
+
+	pw.mu.Lock()
+	defer pw.mu.Unlock()
+
+	buf := &bytes.Buffer{}
+	pw.writeHeader(buf, evt)
+	pw.writeFields(buf, evt)
+	if pw.isCompact {
+		pw.writeCompactErrorDetails(buf, evt)
+	} else {
+		writeErrorDetails(buf, evt, pw.colorize)
+	}
+	buf.WriteByte('\n')
+
+	if _, err := pw.out.Write(buf.Bytes()); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (pw *PrettyWriter) colorize(s, color string) string {
+	if !pw.isColor || color == "" {
+		return s
+	}
+	return color + s + colorReset
+}
+
+func (pw *PrettyWriter) writeHeader(buf *bytes.Buffer, evt map[string]any) {
+	if v, ok := evt[zerolog.TimestampFieldName].(string); ok {
+		if t, err := time.Parse(zerolog.TimeFieldFormat, v); err == nil {
+			v = t.Format(pw.timeFormat)
+		}
+		buf.WriteString(pw.colorize(v, colorGray))
+		buf.WriteByte(' ')
+	}
+
+	lv, _ := evt[zerolog.LevelFieldName].(string)
+	level, ok := prettyLevels[lv]
+	if !ok {
+		level.name = "???"
+	}
+	buf.WriteString(pw.colorize(level.name, level.color))
+	buf.WriteByte(' ')
+
+	if c, ok := evt[zerolog.CallerFieldName].(string); ok {
+		c = shortCaller(c)
+		if !pw.isCompact {
+			if n := utf8.RuneCountInString(c); n > pw.callerWidth && n <= maxPrettyCallerWidth {
+				pw.callerWidth = n
+			}
+			c = padRight(c, pw.callerWidth)
+		}
+		buf.WriteString(pw.colorize(c, colorBold))
+		buf.WriteString(pw.colorize(" >", colorCyan))
+		buf.WriteByte(' ')
+	} else if !pw.isCompact && pw.callerWidth > 0 {
+		buf.WriteString(strings.Repeat(" ", pw.callerWidth+3))
+	}
+
+	msg, _ := evt[zerolog.MessageFieldName].(string)
+	if pw.isCompact {
+		buf.WriteString(msg)
+		return
+	}
+	buf.WriteString(padRight(msg, pw.messageWidth))
+}
+
+func (pw *PrettyWriter) writeFields(buf *bytes.Buffer, evt map[string]any) {
+	for _, name := range pw.fieldNames(evt) {
+		if buf.Len() > 0 {
+			buf.WriteByte(' ')
+		}
+
+		value := pw.formatValue(evt[name])
+		if name == zerolog.ErrorFieldName {
+			buf.WriteString(pw.colorize(name+"=", colorRed))
+			buf.WriteString(pw.colorize(value, colorBoldRed))
+			continue
+		}
+		buf.WriteString(pw.colorize(name+"=", colorCyan))
+		buf.WriteString(value)
+	}
+}
+
+// fieldNames returns the error first, then the prioritized fields and the sorted others
+func (pw *PrettyWriter) fieldNames(evt map[string]any) []string {
+	skip := map[string]bool{
+		zerolog.TimestampFieldName:  true,
+		zerolog.LevelFieldName:      true,
+		zerolog.CallerFieldName:     true,
+		zerolog.MessageFieldName:    true,
+		zerolog.ErrorStackFieldName: true,
+		ErrorChainFieldName:         true,
+	}
+	for _, name := range pw.excluded {
+		skip[name] = true
+	}
+
+	names := make([]string, 0, len(evt))
+	if _, ok := evt[zerolog.ErrorFieldName]; ok {
+		names = append(names, zerolog.ErrorFieldName)
+		skip[zerolog.ErrorFieldName] = true
+	}
+	for _, name := range pw.priority {
+		if _, ok := evt[name]; ok && !skip[name] {
+			names = append(names, name)
+			skip[name] = true
+		}
+	}
+
+	rest := make([]string, 0, len(evt))
+	for name := range evt {
+		if !skip[name] {
+			rest = append(rest, name)
+		}
+	}
+	sort.Strings(rest)
+	return append(names, rest...)
+}
+
+func (pw *PrettyWriter) formatValue(v any) string {
+	var s string
+	switch tv := v.(type) {
+	case string:
+		s = tv
+		if tv == "" || strings.ContainsAny(tv, " \t\n\r\"=") {
+			s = strconv.Quote(tv)
+		}
+	case json.Number:
+		s = tv.String()
+	case nil:
+		s = "null"
+	default:
+		b, err := json.Marshal(tv)
+		if err != nil {
+			s = fmt.Sprint(tv)
+		} else {
+			s = string(b)
+		}
+	}
+	return truncate(s, pw.maxValueLen)
+}
+
+func (pw *PrettyWriter) writeCompactErrorDetails(buf *bytes.Buffer, evt map[string]any) {
+	if chain, ok := evt[ErrorChainFieldName].([]any); ok && len(chain) > 1 {
+		msgs := make([]string, 0, len(chain)-1)
+		for _, msg := range chain[1:] {
+			msgs = append(msgs, strings.ReplaceAll(fmt.Sprint(msg), "\n", "; "))
+		}
+		buf.WriteString(" caused-by=" + strconv.Quote(truncate(strings.Join(msgs, " <- "), pw.maxValueLen)))
+	}
+}
+
+// shortCaller keeps the file name and line only
+func shortCaller(c string) string {
+	if i := strings.LastIndexAny(c, `/\`); i >= 0 {
+		return c[i+1:]
+	}
+	return c
+}
+
+func padRight(s string, width int) string {
+	if n := utf8.RuneCountInString(s); n < width {
+		return s + strings.Repeat(" ", width-n)
+	}
+	return s
+}
+
+func truncate(s string, max int) string {
+	if max <= 0 || utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	r := []rune(s)
+	return string(r[:max]) + fmt.Sprintf("…(+%d)", len(r)-max)
+}
@@ -0,0 +1,64 @@
+package glog_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+
+	glog "github.com/omgolab/go-commons/pkg/log"
+)
+
+// nextLine returns the line following the call, i.e. the caller of the next log call
+func nextLine() int {
+	_, _, line, _ := runtime.Caller(1)
+	return line + 1
+}
+
+func TestPrettyWriter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l, err := glog.New(glog.WithPrettyConsole(
+		glog.WithPrettyOutput(buf),
+		glog.WithPrettyColor(false),
+		glog.WithPrettyFieldPriority("user"),
+		glog.WithPrettyMaxValueLen(20),
+		glog.WithPrettyMessageWidth(10),
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l.Info("hi", glog.LogFields{"b": 1, "a": "x y", "user": "bob", "blob": strings.Repeat("z", 40)})
+	line := nextLine()
+	l.Error("failed", fmt.Errorf("save: %w", errors.New("disk full")))
+
+	lines := strings.Split(buf.String(), "\n")
+	if !strings.Contains(lines[0], ` INF hi         user=bob a="x y" b=1 blob=zzzzzzzzzzzzzzzzzzzz…(+20)`) {
+		t.Errorf("unexpected info line: %q", lines[0])
+	}
+	if !strings.Contains(lines[1], fmt.Sprintf(` ERR pretty_test.go:%d > failed     error="save: disk full"`, line)) {
+		t.Errorf("unexpected error line: %q", lines[1])
+	}
+	if lines[2] != "  caused by: disk full" || !strings.HasPrefix(lines[3], fmt.Sprintf("    at log_test.TestPrettyWriter (pretty_test.go:%d)", line)) {
+		t.Errorf("unexpected error details: %q", lines[2:4])
+	}
+	if strings.Contains(buf.String(), "\x1b[") {
+		t.Error("expected no colors")
+	}
+}
+
+func TestPrettyWriter_Compact(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l, _ := glog.New(glog.WithPrettyConsole(glog.WithPrettyOutput(buf), glog.WithPrettyCompact()))
+
+	line := nextLine()
+	l.Error("failed", fmt.Errorf("save: %w", errors.New("disk full")), glog.LogFields{"id": 1})
+
+	out := strings.TrimSuffix(buf.String(), "\n")
+	want := fmt.Sprintf(` ERR pretty_test.go:%d > failed error="save: disk full" id=1 caused-by="disk full"`, line)
+	if strings.Contains(out, "\n") || !strings.HasSuffix(out, want) {
+		t.Errorf("unexpected compact line: %q", out)
+	}
+}
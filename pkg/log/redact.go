@@ -0,0 +1,184 @@
+package glog
+
+import (
+	"encoding/json"
+	"path"
+	"regexp"
+	"strings"
+)
+
+const (
+	// RedactedText replaces every redacted value
+	RedactedText = "[REDACTED]"
+
+	// FilterTagFieldName is the field routing the events of the filter loggers (see gcustomlog),
+	// it's never redacted so the routing keeps working with any pattern
+	FilterTagFieldName = "filter-tag"
+)
+
+var (
+	// CardNumberPattern matches 13-19 digit card numbers optionally separated by spaces or dashes,
+	// see WithRedactedCardNumbers to keep the numbers failing the Luhn check
+	CardNumberPattern = regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`)
+
+	// JWTPattern matches JSON web tokens
+	JWTPattern = regexp.MustCompile(`\beyJ[A-Za-z0-9_-]*\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`)
+)
+
+// Redacted wraps a value that must never be written to the logs
+type Redacted struct {
+	value any
+}
+
+// Redact wraps v so it's always rendered as RedactedText
+func Redact(v any) Redacted {
+	return Redacted{value: v}
+}
+
+// Value returns the wrapped value
+func (r Redacted) Value() any {
+	return r.value
+}
+
+func (r Redacted) String() string {
+	return RedactedText
+}
+
+func (r Redacted) MarshalText() ([]byte, error) {
+	return []byte(RedactedText), nil
+}
+
+func (r Redacted) MarshalJSON() ([]byte, error) {
+	return json.Marshal(RedactedText)
+}
+
+type redactor struct {
+	fieldPatterns []string
+	valuePatterns []valuePattern
+}
+
+// valuePattern is a redacted value pattern, the luhn matches are only redacted when they
+// pass the Luhn check (i.e. card numbers)
+type valuePattern struct {
+	re   *regexp.Regexp
+	luhn bool
+}
+
+// redactedError has the redacted message and keeps the error for errors.Is and errors.As
+type redactedError struct {
+	msg string
+	err error
+}
+
+func (e *redactedError) Error() string {
+	return e.msg
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
+
+func (r *redactor) isEnabled() bool {
+	return r != nil && (len(r.fieldPatterns) > 0 || len(r.valuePatterns) > 0)
+}
+
+// isSensitiveField matches the field name against the case insensitive glob patterns
+func (r *redactor) isSensitiveField(name string) bool {
+	name = strings.ToLower(name)
+	for _, p := range r.fieldPatterns {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *redactor) redactString(s string) string {
+	for _, p := range r.valuePatterns {
+		if p.luhn {
+			s = p.re.ReplaceAllStringFunc(s, func(m string) string {
+				if !isLuhnValid(m) {
+					return m
+				}
+				return RedactedText
+			})
+			continue
+		}
+		s = p.re.ReplaceAllString(s, RedactedText)
+	}
+	return s
+}
+
+// isLuhnValid checks the digits of s (the separators are skipped) with the Luhn algorithm
+func isLuhnValid(s string) bool {
+	sum, n := 0, 0
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if n%2 == 1 {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		n++
+	}
+	return n > 0 && sum%10 == 0
+}
+
+func (r *redactor) redactErr(err error) error {
+	if err == nil || len(r.valuePatterns) == 0 {
+		return err
+	}
+	if msg := err.Error(); r.redactString(msg) != msg {
+		return &redactedError{msg: r.redactString(msg), err: err}
+	}
+	return err
+}
+
+// redactFields returns a redacted copy of the fields
+func (r *redactor) redactFields(fields map[string]any) map[string]any {
+	redacted := make(map[string]any, len(fields))
+	for k, v := range fields {
+		if k == FilterTagFieldName {
+			redacted[k] = v
+			continue
+		}
+		if r.isSensitiveField(k) {
+			redacted[k] = RedactedText
+			continue
+		}
+		redacted[k] = r.redactValue(v)
+	}
+	return redacted
+}
+
+func (r *redactor) redactValue(v any) any {
+	switch tv := v.(type) {
+	case string:
+		return r.redactString(tv)
+	case LogFields:
+		return r.redactFields(tv)
+	case map[string]any:
+		return r.redactFields(tv)
+	case []string:
+		s := make([]string, len(tv))
+		for i, e := range tv {
+			s[i] = r.redactString(e)
+		}
+		return s
+	case []any:
+		s := make([]any, len(tv))
+		for i, e := range tv {
+			s[i] = r.redactValue(e)
+		}
+		return s
+	case error:
+		return r.redactErr(tv)
+	default:
+		return v
+	}
+}
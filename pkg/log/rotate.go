@@ -0,0 +1,125 @@
+package glog
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	gfile "github.com/omgolab/go-commons/pkg/file/open"
+)
+
+// RotatingFile is a file writer rotating the file once it exceeds maxSize bytes,
+// keeping up to maxBackups old files suffixed `.1` (newest) to `.N` (oldest)
+type RotatingFile struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+
+	mu   sync.Mutex
+	f    *os.File
+	size int64
+}
+
+// NewRotatingFile opens (or creates) the file in append mode
+func NewRotatingFile(path string, maxSize int64, maxBackups int) (*RotatingFile, error) {
+	if maxSize <= 0 || maxBackups < 0 {
+		return nil, fmt.Errorf("glog: invalid rotation settings for %s", path)
+	}
+
+	rf := &RotatingFile{path: path, maxSize: maxSize, maxBackups: maxBackups}
+	if err := rf.open(); err != nil {
+		return nil, err
+	}
+	return rf, nil
+}
+
+func (rf *RotatingFile) open() error {
+	f, err := gfile.OpenFile(rf.path)
+	if err != nil {
+		return err
+	}
+	s, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	rf.f, rf.size = f, s.Size()
+	return nil
+}
+
+func (rf *RotatingFile) Write(p []byte) (int, error) {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	// the file is reopened after a failed rotation
+	if rf.f == nil {
+		if err := rf.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	// the event still goes to the reopened file when the rotation fails
+	var rerr error
+	if rf.size > 0 && rf.size+int64(len(p)) > rf.maxSize {
+		rerr = rf.rotateLocked()
+		if rf.f == nil {
+			return 0, rerr
+		}
+	}
+
+	n, err := rf.f.Write(p)
+	rf.size += int64(n)
+	return n, errors.Join(rerr, err)
+}
+
+// rotateLocked shifts the backups by one and starts a new file, the base file is
+// reopened whatever fails so the writes go on
+func (rf *RotatingFile) rotateLocked() error {
+	err := rf.f.Close()
+	rf.f = nil
+	err = errors.Join(err, rf.shiftBackups())
+	if oerr := rf.open(); oerr != nil {
+		return errors.Join(err, oerr)
+	}
+	return err
+}
+
+func (rf *RotatingFile) shiftBackups() error {
+	if rf.maxBackups == 0 {
+		if err := os.Remove(rf.path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	_ = os.Remove(fmt.Sprintf("%s.%d", rf.path, rf.maxBackups))
+	for i := rf.maxBackups - 1; i >= 1; i-- {
+		src := fmt.Sprintf("%s.%d", rf.path, i)
+		if err := os.Rename(src, fmt.Sprintf("%s.%d", rf.path, i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return os.Rename(rf.path, rf.path+".1")
+}
+
+func (rf *RotatingFile) Close() error {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+	if rf.f == nil {
+		return nil
+	}
+	err := rf.f.Close()
+	rf.f = nil
+	return err
+}
+
+// Sync commits the current file to the disk
+func (rf *RotatingFile) Sync() error {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+	if rf.f == nil {
+		return nil
+	}
+	return rf.f.Sync()
+}
@@ -0,0 +1,490 @@
+package glog
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultShipperBatchSize     = 100
+	defaultShipperFlushInterval = time.Second
+	defaultShipperMaxRetries    = 5
+	defaultShipperMinBackoff    = 100 * time.Millisecond
+	defaultShipperMaxBackoff    = 10 * time.Second
+	defaultShipperMaxPending    = 10000
+	defaultShipperTimeout       = 30 * time.Second
+	spoolFilePrefix             = "glog-spool-"
+	spoolFileSuffix             = ".jsonl"
+	// RejectedSpoolSuffix is appended to the spooled batches refused by the collector (e.g. HTTP 400)
+	RejectedSpoolSuffix = ".rejected"
+)
+
+var (
+	// errPermanent marks the send errors that must not be retried (e.g. HTTP 400)
+	errPermanent = errors.New("glog: permanent shipping error")
+
+	ErrShipperClosed = errors.New("glog: shipper is closed")
+)
+
+type ShipperOption func(*Shipper) error
+
+// Shipper is an io.Writer shipping the JSON lines in batches to a collector over TCP or HTTP.
+// Failed batches are retried with an exponential backoff and spooled to disk if a spool
+// directory is set; the spooled batches are resent once the collector is back.
+type Shipper struct {
+	url           *url.URL
+	client        *http.Client
+	headers       http.Header
+	batchSize     int
+	flushInterval time.Duration
+	maxRetries    int
+	minBackoff    time.Duration
+	maxBackoff    time.Duration
+	maxPending    int
+	isGzip        bool
+	spoolDir      string
+	onError       func(error)
+
+	mu       sync.Mutex
+	pending  [][]byte
+	isClosed bool
+	conn     net.Conn
+	spoolSeq uint64
+
+	sendMu  sync.Mutex
+	flushCh chan struct{}
+	done    chan struct{}
+	wg      sync.WaitGroup
+	once    sync.Once
+}
+
+// WithShipperBatchSize sets the number of lines sent at once
+func WithShipperBatchSize(n int) ShipperOption {
+	return func(s *Shipper) error {
+		if n <= 0 {
+			return fmt.Errorf("glog: invalid shipper batch size %d", n)
+		}
+		s.batchSize = n
+		return nil
+	}
+}
+
+// WithShipperFlushInterval sets the max time a line waits before being sent
+func WithShipperFlushInterval(d time.Duration) ShipperOption {
+	return func(s *Shipper) error {
+		if d <= 0 {
+			return fmt.Errorf("glog: invalid shipper flush interval %s", d)
+		}
+		s.flushInterval = d
+		return nil
+	}
+}
+
+// WithShipperRetry sets the retries of a failed batch with a backoff doubling from min up to max
+func WithShipperRetry(maxRetries int, minBackoff, maxBackoff time.Duration) ShipperOption {
+	return func(s *Shipper) error {
+		if maxRetries < 0 || minBackoff <= 0 || maxBackoff < minBackoff {
+			return errors.New("glog: invalid shipper retry settings")
+		}
+		s.maxRetries, s.minBackoff, s.maxBackoff = maxRetries, minBackoff, maxBackoff
+		return nil
+	}
+}
+
+// WithShipperGzip compresses the HTTP request bodies
+func WithShipperGzip() ShipperOption {
+	return func(s *Shipper) error {
+		s.isGzip = true
+		return nil
+	}
+}
+
+// WithShipperSpoolDir spools the batches to dir when the collector is down
+func WithShipperSpoolDir(dir string) ShipperOption {
+	return func(s *Shipper) error {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+		s.spoolDir = dir
+		return nil
+	}
+}
+
+// WithShipperHTTPClient sets the HTTP client, e.g. for TLS settings
+func WithShipperHTTPClient(c *http.Client) ShipperOption {
+	return func(s *Shipper) error {
+		s.client = c
+		return nil
+	}
+}
+
+// WithShipperHeader adds an HTTP header, e.g. for authorization
+func WithShipperHeader(key, value string) ShipperOption {
+	return func(s *Shipper) error {
+		s.headers.Add(key, value)
+		return nil
+	}
+}
+
+// WithShipperMaxPending sets the number of buffered lines over which new lines
+// are spooled (or dropped without a spool directory) until the collector catches up
+func WithShipperMaxPending(n int) ShipperOption {
+	return func(s *Shipper) error {
+		if n <= 0 {
+			return fmt.Errorf("glog: invalid shipper max pending %d", n)
+		}
+		s.maxPending = n
+		return nil
+	}
+}
+
+// WithShipperErrorHandler sets the handler of the shipping errors, stderr by default
+func WithShipperErrorHandler(fn func(error)) ShipperOption {
+	return func(s *Shipper) error {
+		s.onError = fn
+		return nil
+	}
+}
+
+// NewShipper returns a started shipper for a "tcp://host:port" or "http(s)://..." endpoint.
+// Remember to call Close to flush the pending lines.
+func NewShipper(endpoint string, opts ...ShipperOption) (*Shipper, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	switch u.Scheme {
+	case "tcp", "http", "https":
+	default:
+		return nil, fmt.Errorf("glog: unsupported shipper endpoint %q", endpoint)
+	}
+
+	s := &Shipper{
+		url:           u,
+		client:        &http.Client{Timeout: defaultShipperTimeout},
+		headers:       http.Header{},
+		batchSize:     defaultShipperBatchSize,
+		flushInterval: defaultShipperFlushInterval,
+		maxRetries:    defaultShipperMaxRetries,
+		minBackoff:    defaultShipperMinBackoff,
+		maxBackoff:    defaultShipperMaxBackoff,
+		maxPending:    defaultShipperMaxPending,
+		onError: func(err error) {
+			fmt.Fprintln(os.Stderr, err)
+		},
+		flushCh: make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+	for _, opt := range opts {
+		if err := opt(s); err != nil {
+			return nil, err
+		}
+	}
+
+	s.wg.Add(1)
+	go s.run()
+	return s, nil
+}
+
+// Write buffers a copy of the line, it never blocks on the network.
+// When the buffer is full, the buffered lines are spooled at once.
+func (s *Shipper) Write(p []byte) (int, error) {
+	line := make([]byte, len(p), len(p)+1)
+	copy(line, p)
+	if len(line) == 0 || line[len(line)-1] != '\n' {
+		line = append(line, '\n')
+	}
+
+	s.mu.Lock()
+	if s.isClosed {
+		s.mu.Unlock()
+		return 0, ErrShipperClosed
+	}
+	if len(s.pending) >= s.maxPending {
+		if s.spoolDir == "" {
+			s.mu.Unlock()
+			return 0, errors.New("glog: shipper buffer is full")
+		}
+		// the spool is sent before the pending lines, so they go with it to keep the order
+		batch := append(s.pending, line)
+		s.pending = nil
+		err := s.spoolLocked(batch)
+		s.mu.Unlock()
+		if err != nil {
+			return 0, err
+		}
+		s.triggerFlush()
+		return len(p), nil
+	}
+	s.pending = append(s.pending, line)
+	isFull := len(s.pending) >= s.batchSize
+	s.mu.Unlock()
+
+	if isFull {
+		s.triggerFlush()
+	}
+	return len(p), nil
+}
+
+func (s *Shipper) triggerFlush() {
+	select {
+	case s.flushCh <- struct{}{}:
+	default:
+	}
+}
+
+func (s *Shipper) run() {
+	defer s.wg.Done()
+	ticker := time.NewTicker(s.flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+		case <-s.flushCh:
+		}
+		if err := s.Flush(); err != nil {
+			s.onError(err)
+		}
+	}
+}
+
+// Flush sends the spooled batches then the pending lines, the pending lines
+// wait while the spool can't be sent so the lines are delivered in order
+func (s *Shipper) Flush() error {
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+
+	if err := s.resendSpooled(); err != nil {
+		return err
+	}
+
+	for {
+		s.mu.Lock()
+		n := len(s.pending)
+		if n > s.batchSize {
+			n = s.batchSize
+		}
+		batch := s.pending[:n:n]
+		s.pending = s.pending[n:]
+		s.mu.Unlock()
+
+		if len(batch) == 0 {
+			break
+		}
+		if err := s.sendWithRetry(batch); err != nil {
+			if s.spoolDir == "" || errors.Is(err, errPermanent) {
+				return fmt.Errorf("glog: dropped %d log lines: %w", len(batch), err)
+			}
+			if serr := s.spool(batch); serr != nil {
+				return errors.Join(err, serr)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Shipper) sendWithRetry(batch [][]byte) error {
+	body := bytes.Join(batch, nil)
+	backoff := s.minBackoff
+
+	var err error
+	for attempt := 0; ; attempt++ {
+		if err = s.send(body); err == nil || errors.Is(err, errPermanent) || attempt >= s.maxRetries {
+			return err
+		}
+
+		time.Sleep(backoff)
+		backoff *= 2
+		if backoff > s.maxBackoff {
+			backoff = s.maxBackoff
+		}
+	}
+}
+
+func (s *Shipper) send(body []byte) error {
+	if s.url.Scheme == "tcp" {
+		return s.sendTCP(body)
+	}
+	return s.sendHTTP(body)
+}
+
+func (s *Shipper) sendTCP(body []byte) (err error) {
+	timeout := s.client.Timeout
+	if timeout <= 0 {
+		timeout = defaultShipperTimeout
+	}
+	if s.conn == nil {
+		if s.conn, err = net.DialTimeout("tcp", s.url.Host, timeout); err != nil {
+			return err
+		}
+	}
+	// a stuck collector must not block Flush and Close forever
+	if err = s.conn.SetWriteDeadline(time.Now().Add(timeout)); err == nil {
+		_, err = s.conn.Write(body)
+	}
+	if err != nil {
+		_ = s.conn.Close()
+		s.conn = nil
+	}
+	return err
+}
+
+func (s *Shipper) sendHTTP(body []byte) error {
+	var r io.Reader = bytes.NewReader(body)
+	if s.isGzip {
+		buf := &bytes.Buffer{}
+		zw := gzip.NewWriter(buf)
+		if _, err := zw.Write(body); err != nil {
+			return err
+		}
+		if err := zw.Close(); err != nil {
+			return err
+		}
+		r = buf
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.url.String(), r)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errPermanent, err)
+	}
+	for k, v := range s.headers {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	if s.isGzip {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return fmt.Errorf("glog: collector responded %s", resp.Status)
+	default:
+		return fmt.Errorf("%w: collector responded %s", errPermanent, resp.Status)
+	}
+}
+
+func (s *Shipper) spool(batch [][]byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.spoolLocked(batch)
+}
+
+// spoolLocked writes the batch to a new spool file named by time and sequence,
+// so the names sort in the write order. The file is renamed once complete.
+func (s *Shipper) spoolLocked(batch [][]byte) error {
+	s.spoolSeq++
+	f, err := os.CreateTemp(s.spoolDir, fmt.Sprintf("tmp-%020d-%010d-*", time.Now().UnixNano(), s.spoolSeq))
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(bytes.Join(batch, nil))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		// the temporary name is kept for uniqueness across the processes sharing the directory
+		err = os.Rename(f.Name(), filepath.Join(s.spoolDir, spoolFilePrefix+strings.TrimPrefix(filepath.Base(f.Name()), "tmp-")+spoolFileSuffix))
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+	}
+	return err
+}
+
+// resendSpooled sends the spooled batches oldest first and stops on the first failure,
+// the batches refused by the collector are reported and renamed with RejectedSpoolSuffix.
+// It runs under sendMu so a batch is never sent twice
+func (s *Shipper) resendSpooled() error {
+	if s.spoolDir == "" {
+		return nil
+	}
+
+	entries, err := os.ReadDir(s.spoolDir)
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasPrefix(e.Name(), spoolFilePrefix) && strings.HasSuffix(e.Name(), spoolFileSuffix) {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		path := filepath.Join(s.spoolDir, name)
+		body, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if err := s.send(body); err != nil {
+			if !errors.Is(err, errPermanent) {
+				return err
+			}
+			// moved aside so it doesn't block the next batches
+			s.onError(fmt.Errorf("glog: spooled batch %s rejected: %w", name, err))
+			if err := os.Rename(path, path+RejectedSpoolSuffix); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close stops the shipper after flushing the pending lines, the lines
+// which can't be sent are spooled if a spool directory is set
+func (s *Shipper) Close() (err error) {
+	s.once.Do(func() {
+		s.mu.Lock()
+		s.isClosed = true
+		s.mu.Unlock()
+
+		close(s.done)
+		s.wg.Wait()
+		if err = s.Flush(); err != nil && s.spoolDir != "" {
+			s.mu.Lock()
+			if len(s.pending) > 0 {
+				err = errors.Join(err, s.spoolLocked(s.pending))
+				s.pending = nil
+			}
+			s.mu.Unlock()
+		}
+
+		s.sendMu.Lock()
+		if s.conn != nil {
+			err = errors.Join(err, s.conn.Close())
+			s.conn = nil
+		}
+		s.sendMu.Unlock()
+	})
+	return err
+}
@@ -0,0 +1,243 @@
+package glog_test
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	glog "github.com/omgolab/go-commons/pkg/log"
+)
+
+type collector struct {
+	mu    sync.Mutex
+	lines []string
+	fails atomic.Int32
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if c.fails.Add(-1) >= 0 {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	var body io.Reader = r.Body
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		body = zr
+	}
+	b, _ := io.ReadAll(body)
+
+	c.mu.Lock()
+	c.lines = append(c.lines, strings.Split(strings.TrimSpace(string(b)), "\n")...)
+	c.mu.Unlock()
+}
+
+func (c *collector) received() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string{}, c.lines...)
+}
+
+func TestShipper_HTTPRetriesWithGzip(t *testing.T) {
+	c := &collector{}
+	c.fails.Store(2)
+	srv := httptest.NewServer(c)
+	defer srv.Close()
+
+	s, err := glog.NewShipper(srv.URL,
+		glog.WithShipperGzip(),
+		glog.WithShipperBatchSize(2),
+		glog.WithShipperRetry(3, time.Millisecond, 5*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, _ := glog.New(glog.WithWriters(s))
+
+	l.Info("one")
+	l.Info("two")
+	l.Info("three")
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	got := c.received()
+	if len(got) != 3 || !strings.Contains(got[0], `"one"`) || !strings.Contains(got[2], `"three"`) {
+		t.Errorf("unexpected shipped lines: %v", got)
+	}
+}
+
+func TestShipper_SpoolsWhileCollectorIsDown(t *testing.T) {
+	c := &collector{}
+	c.fails.Store(1)
+	srv := httptest.NewServer(c)
+	defer srv.Close()
+
+	dir := t.TempDir()
+	s, err := glog.NewShipper(srv.URL,
+		glog.WithShipperSpoolDir(dir),
+		glog.WithShipperRetry(0, time.Millisecond, time.Millisecond),
+		glog.WithShipperErrorHandler(func(error) {}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, _ = s.Write([]byte(`{"message":"spooled"}`))
+	if err := s.Flush(); err == nil {
+		t.Fatal("expected the first flush to fail")
+	}
+	if files, _ := os.ReadDir(dir); len(files) != 1 {
+		t.Fatalf("expected 1 spooled file, got %d", len(files))
+	}
+
+	_, _ = s.Write([]byte(`{"message":"live"}`))
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if files, _ := os.ReadDir(dir); len(files) != 0 {
+		t.Errorf("expected the spool to be drained, got %d files", len(files))
+	}
+	// the spooled line was logged first so it's delivered first
+	if got := c.received(); len(got) != 2 || !strings.Contains(got[0], "spooled") || !strings.Contains(got[1], "live") {
+		t.Errorf("expected the spooled then the live lines, got %v", got)
+	}
+}
+
+func TestShipper_RejectedSpoolAndClose(t *testing.T) {
+	c := &collector{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		if strings.Contains(string(b), "poison") {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		r.Body = io.NopCloser(strings.NewReader(string(b)))
+		c.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	var reported atomic.Int32
+	s, err := glog.NewShipper(srv.URL,
+		glog.WithShipperSpoolDir(dir),
+		glog.WithShipperMaxPending(2),
+		glog.WithShipperFlushInterval(time.Hour),
+		glog.WithShipperRetry(0, time.Millisecond, time.Millisecond),
+		glog.WithShipperErrorHandler(func(error) { reported.Add(1) }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the full buffer is spooled as a single batch with the new line, which the collector rejects
+	for _, m := range []string{"a", "b", "poison"} {
+		if _, err := s.Write([]byte(`{"message":"` + m + `"}`)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, _ = s.Write([]byte(`{"message":"c"}`))
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the rejected batch is moved aside and doesn't block the next lines
+	files, _ := os.ReadDir(dir)
+	if len(files) != 1 || !strings.HasSuffix(files[0].Name(), glog.RejectedSpoolSuffix) || reported.Load() == 0 {
+		t.Fatalf("expected the rejected batch to be reported and renamed, got %v", files)
+	}
+	if got := c.received(); len(got) != 1 || !strings.Contains(got[0], `"c"`) {
+		t.Errorf("expected the line written after the rejected batch, got %v", got)
+	}
+	if _, err := s.Write([]byte(`{"message":"late"}`)); err != glog.ErrShipperClosed {
+		t.Errorf("expected ErrShipperClosed, got %v", err)
+	}
+}
+
+func TestShipper_TCPWriteDeadline(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	// the peer accepts but never reads
+	go func() {
+		conn, err := ln.Accept()
+		if err == nil {
+			defer conn.Close()
+			time.Sleep(5 * time.Second)
+		}
+	}()
+
+	s, err := glog.NewShipper("tcp://"+ln.Addr().String(),
+		glog.WithShipperHTTPClient(&http.Client{Timeout: 100 * time.Millisecond}),
+		glog.WithShipperRetry(0, time.Millisecond, time.Millisecond),
+		glog.WithShipperFlushInterval(time.Hour),
+		glog.WithShipperErrorHandler(func(error) {}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = s.Write([]byte(strings.Repeat("x", 64<<20)))
+
+	start := time.Now()
+	if err := s.Close(); err == nil {
+		t.Error("expected the stuck write to fail")
+	}
+	if d := time.Since(start); d > 3*time.Second {
+		t.Errorf("expected Close to give up at the write deadline, took %s", d)
+	}
+}
+
+func TestShipper_TCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	lines := make(chan string, 2)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		sc := bufio.NewScanner(conn)
+		for sc.Scan() {
+			lines <- sc.Text()
+		}
+	}()
+
+	s, err := glog.NewShipper("tcp://"+ln.Addr().String(), glog.WithShipperFlushInterval(time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	l, _ := glog.New(glog.WithWriters(s))
+	l.Info("over tcp")
+
+	select {
+	case line := <-lines:
+		if !strings.Contains(line, `"message":"over tcp"`) {
+			t.Errorf("unexpected line: %s", line)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for the shipped line")
+	}
+}
@@ -0,0 +1,81 @@
+package glog
+
+import (
+	"context"
+	"errors"
+	"os"
+	"time"
+)
+
+const defaultShutdownTimeout = 5 * time.Second
+
+// ShutdownHook is run by Fatal before exiting, it should return once ctx is done
+type ShutdownHook func(ctx context.Context)
+
+// terminate implements the Fatal and Panic semantics once the event is logged
+func (l *logCfg) terminate(msg string, level LogLevel) {
+	switch level {
+	case FatalLevel:
+		l.shutdown()
+		l.sc.mu.RLock()
+		exit := l.sc.exitFn
+		l.sc.mu.RUnlock()
+		exit(1)
+	case PanicLevel:
+		_ = l.flushWriters()
+		panic(msg)
+	}
+}
+
+// shutdown flushes the writers then runs the hooks in reverse order, giving up at the timeout
+func (l *logCfg) shutdown() {
+	l.sc.mu.RLock()
+	hooks := l.sc.shutdownHooks
+	timeout := l.sc.shutdownTimeout
+	l.sc.mu.RUnlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = l.flushWriters()
+		for i := len(hooks) - 1; i >= 0 && ctx.Err() == nil; i-- {
+			hooks[i](ctx)
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+}
+
+// Flush writes the duplicates held back by WithDedup, flushes the buffered writers
+// (e.g. Shipper) and syncs the files; call it before a normal exit
+func (l *logCfg) Flush() error {
+	return l.flushWriters()
+}
+
+// flushWriters flushes the held back duplicates, the buffered writers (e.g. Shipper) and syncs the files
+func (l *logCfg) flushWriters() error {
+	if d := l.deduperSnapshot(); d != nil {
+		d.flush()
+	}
+
+	var errs []error
+	for _, w := range l.writersSnapshot() {
+		switch fw := w.(type) {
+		case Logger:
+			// e.g. the filter loggers are writers of their own base logger, flushing would recurse
+		case interface{ Flush() error }:
+			errs = append(errs, fw.Flush())
+		case interface{ Sync() error }:
+			if fw != os.Stdout && fw != os.Stderr {
+				errs = append(errs, fw.Sync())
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
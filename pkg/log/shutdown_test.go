@@ -0,0 +1,55 @@
+package glog_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	glog "github.com/omgolab/go-commons/pkg/log"
+	glogtest "github.com/omgolab/go-commons/pkg/log/test"
+)
+
+func TestFatal_RunsShutdownHooksThenExits(t *testing.T) {
+	var calls []string
+	l := glogtest.New(t, glog.WithShutdownTimeout(50*time.Millisecond))
+	l.AddShutdownHook(func(ctx context.Context) { calls = append(calls, "first") })
+	l.AddShutdownHook(func(ctx context.Context) { calls = append(calls, "second") })
+
+	l.Fatal("cannot start", errors.New("port in use"))
+
+	l.AssertLogged(t, glog.FatalLevel, "cannot start", nil)
+	if len(calls) != 2 || calls[0] != "second" || calls[1] != "first" {
+		t.Errorf("expected the hooks to run in reverse order, got %v", calls)
+	}
+	if codes := l.ExitCodes(); len(codes) != 1 || codes[0] != 1 {
+		t.Errorf("expected a single exit with code 1, got %v", codes)
+	}
+}
+
+func TestFatal_ExitsAfterTheTimeout(t *testing.T) {
+	l := glogtest.New(t, glog.WithShutdownTimeout(10*time.Millisecond))
+	release := make(chan struct{})
+	defer close(release)
+	l.AddShutdownHook(func(ctx context.Context) { <-release })
+
+	start := time.Now()
+	l.DisableAllLoggers()
+	l.Fatal("stuck", nil)
+
+	if time.Since(start) > time.Second || len(l.ExitCodes()) != 1 {
+		t.Errorf("expected to exit after the timeout even when the logger is disabled")
+	}
+}
+
+func TestPanic_PanicsWithTheMessage(t *testing.T) {
+	l := glogtest.New(t)
+	defer func() {
+		if r := recover(); r != "invariant broken" {
+			t.Errorf("expected a panic with the message, got %v", r)
+		}
+		l.AssertLogged(t, glog.PanicLevel, "invariant broken", nil)
+	}()
+
+	l.Panic("invariant broken", nil)
+}
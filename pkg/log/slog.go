@@ -0,0 +1,264 @@
+package glog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"runtime"
+	"sort"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// slogHandlerCallerSkip is the frame count from slogHandler.Handle to the
+// caller of the slog.Logger level methods (Handle <- log <- Info <- caller),
+// only used for the records without a PC
+const slogHandlerCallerSkip = 4
+
+// callerPCCtxKey passes the record PC to the logger, it stays right when the handler is wrapped
+const callerPCCtxKey LogStr = "caller-pc"
+
+type slogGroupedAttrs struct {
+	groups []string
+	attrs  []slog.Attr
+}
+
+type slogHandler struct {
+	l      Logger
+	groups []string
+	preset []slogGroupedAttrs
+}
+
+// NewSlogHandler returns a slog.Handler that writes the records through the given glog.Logger.
+// Groups are rendered as nested fields and an error attr keyed "err" or "error" at the top level
+// is logged as the event error.
+func NewSlogHandler(l Logger) slog.Handler {
+	return &slogHandler{l: l}
+}
+
+func (h *slogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.l.IsEnabled(slogToLogLevel(level))
+}
+
+func (h *slogHandler) Handle(ctx context.Context, r slog.Record) error {
+	fields := LogFields{}
+	for _, p := range h.preset {
+		addSlogAttrs(slogGroupTarget(fields, p.groups), p.attrs)
+	}
+
+	attrs := make([]slog.Attr, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+
+	var err error
+	if len(h.groups) == 0 {
+		attrs, err = popSlogError(attrs)
+	}
+	addSlogAttrs(slogGroupTarget(fields, h.groups), attrs)
+
+	if r.PC != 0 {
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		ctx = context.WithValue(ctx, callerPCCtxKey, r.PC)
+	}
+	h.l.EventCtx(ctx, r.Message, slogToLogLevel(r.Level), err, slogHandlerCallerSkip, fields)
+	return nil
+}
+
+// callerFromContext returns the caller of the record PC set by the slog handler
+func callerFromContext(ctx context.Context) (string, bool) {
+	if ctx == nil {
+		return "", false
+	}
+	pc, ok := ctx.Value(callerPCCtxKey).(uintptr)
+	if !ok {
+		return "", false
+	}
+	f, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	return zerolog.CallerMarshalFunc(pc, f.File, f.Line), true
+}
+
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	nh := h.clone()
+	nh.preset = append(nh.preset, slogGroupedAttrs{groups: h.groups, attrs: attrs})
+	return nh
+}
+
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	nh := h.clone()
+	nh.groups = append(nh.groups, name)
+	return nh
+}
+
+func (h *slogHandler) clone() *slogHandler {
+	return &slogHandler{
+		l:      h.l,
+		groups: append([]string{}, h.groups...),
+		preset: append([]slogGroupedAttrs{}, h.preset...),
+	}
+}
+
+// slogGroupTarget returns the nested map for the given groups, creating it if needed
+func slogGroupTarget(fields map[string]any, groups []string) map[string]any {
+	target := fields
+	for _, g := range groups {
+		next, ok := target[g].(map[string]any)
+		if !ok {
+			next = map[string]any{}
+			target[g] = next
+		}
+		target = next
+	}
+	return target
+}
+
+func addSlogAttrs(target map[string]any, attrs []slog.Attr) {
+	for _, a := range attrs {
+		a.Value = a.Value.Resolve()
+		if a.Equal(slog.Attr{}) {
+			continue
+		}
+
+		if a.Value.Kind() != slog.KindGroup {
+			target[a.Key] = a.Value.Any()
+			continue
+		}
+
+		group := a.Value.Group()
+		if len(group) == 0 {
+			continue
+		}
+		if a.Key == "" {
+			addSlogAttrs(target, group)
+			continue
+		}
+		addSlogAttrs(slogGroupTarget(target, []string{a.Key}), group)
+	}
+}
+
+// popSlogError removes the first error attr keyed "err" or "error" and returns it
+func popSlogError(attrs []slog.Attr) ([]slog.Attr, error) {
+	for i, a := range attrs {
+		if a.Key != "err" && a.Key != "error" {
+			continue
+		}
+		if err, ok := a.Value.Resolve().Any().(error); ok {
+			return append(attrs[:i:i], attrs[i+1:]...), err
+		}
+	}
+	return attrs, nil
+}
+
+func slogToLogLevel(level slog.Level) LogLevel {
+	switch {
+	case level < slog.LevelDebug:
+		return TraceLevel
+	case level < slog.LevelInfo:
+		return DebugLevel
+	case level < slog.LevelWarn:
+		return InfoLevel
+	case level < slog.LevelError:
+		return WarnLevel
+	default:
+		return ErrorLevel
+	}
+}
+
+func logToSlogLevel(level LogLevel) slog.Level {
+	switch level {
+	case TraceLevel:
+		return slog.LevelDebug - 4
+	case DebugLevel:
+		return slog.LevelDebug
+	case WarnLevel:
+		return slog.LevelWarn
+	case ErrorLevel:
+		return slog.LevelError
+	case FatalLevel:
+		return slog.LevelError + 4
+	case PanicLevel:
+		return slog.LevelError + 8
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// slogWriter decodes the zerolog JSON events and forwards them to a slog.Handler
+type slogWriter struct {
+	h slog.Handler
+}
+
+// NewSlogLogger returns a glog.Logger that writes every event to the given slog.Handler
+// instead of the default console writer. Additional writers may still be added with options.
+func NewSlogLogger(h slog.Handler, options ...LogOption) (Logger, error) {
+	if h == nil {
+		return nil, errors.New("glog: slog handler is nil")
+	}
+
+	options = append([]LogOption{WithWriters(&slogWriter{h: h})}, options...)
+	return New(options...)
+}
+
+func (w *slogWriter) Write(b []byte) (int, error) {
+	var evt map[string]any
+	if err := json.Unmarshal(b, &evt); err != nil {
+		return 0, err
+	}
+
+	level := slog.LevelInfo
+	if lv, ok := evt[zerolog.LevelFieldName].(string); ok {
+		if zl, err := zerolog.ParseLevel(lv); err == nil {
+			level = logToSlogLevel(zerologToLogLevel(zl))
+		}
+	}
+	delete(evt, zerolog.LevelFieldName)
+
+	ctx := context.Background()
+	if !w.h.Enabled(ctx, level) {
+		return len(b), nil
+	}
+
+	ts := time.Now()
+	if v, ok := evt[zerolog.TimestampFieldName].(string); ok {
+		if t, err := time.Parse(zerolog.TimeFieldFormat, v); err == nil {
+			ts = t
+		}
+	}
+	delete(evt, zerolog.TimestampFieldName)
+
+	msg, _ := evt[zerolog.MessageFieldName].(string)
+	delete(evt, zerolog.MessageFieldName)
+
+	keys := make([]string, 0, len(evt))
+	for k := range evt {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	r := slog.NewRecord(ts, level, msg, 0)
+	for _, k := range keys {
+		r.AddAttrs(slog.Any(k, evt[k]))
+	}
+
+	return len(b), w.h.Handle(ctx, r)
+}
+
+func zerologToLogLevel(zl zerolog.Level) LogLevel {
+	for k, v := range logToZerologMap {
+		if v == zl {
+			return k
+		}
+	}
+	return NoLevel
+}
@@ -0,0 +1,100 @@
+package glog_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+
+	glog "github.com/omgolab/go-commons/pkg/log"
+)
+
+func TestSlogHandler_MapsLevelsGroupsAndAttrs(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l, err := glog.New(glog.WithMultiLogger(buf), glog.WithDefaultLogLevel(glog.InfoLevel))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sl := slog.New(glog.NewSlogHandler(l)).With("svc", "api").WithGroup("req")
+	sl.Debug("skipped")
+	sl.Warn("slow", "ms", 120, slog.Group("user", "id", 7))
+	slog.New(glog.NewSlogHandler(l)).Error("failed", "err", errors.New("boom"))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d: %v", len(lines), lines)
+	}
+
+	var warn map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &warn); err != nil {
+		t.Fatal(err)
+	}
+	if warn["level"] != "warn" || warn["svc"] != "api" {
+		t.Errorf("unexpected level or attrs: %v", warn)
+	}
+	req, _ := warn["req"].(map[string]any)
+	user, _ := req["user"].(map[string]any)
+	if req["ms"] != float64(120) || user["id"] != float64(7) {
+		t.Errorf("unexpected group rendering: %v", warn)
+	}
+
+	var failed map[string]any
+	if err := json.Unmarshal([]byte(lines[1]), &failed); err != nil {
+		t.Fatal(err)
+	}
+	if failed["level"] != "error" || failed["error"] != "boom" {
+		t.Errorf("unexpected error rendering: %v", failed)
+	}
+}
+
+func TestNewSlogLogger_WritesToHandler(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l, err := glog.NewSlogLogger(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l.Warn("disk low", glog.LogFields{"free": 10})
+
+	var got map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got["level"] != "WARN" || got["msg"] != "disk low" || got["free"] != float64(10) {
+		t.Errorf("unexpected slog record: %v", got)
+	}
+}
+
+// wrappedHandler adds frames between slog and the glog handler, like a middleware
+type wrappedHandler struct {
+	slog.Handler
+}
+
+func (h wrappedHandler) Handle(ctx context.Context, r slog.Record) error {
+	return h.Handler.Handle(ctx, r)
+}
+
+func TestSlogHandler_CallerFromRecord(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l, err := glog.New(glog.WithWriters(buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	line := nextLine()
+	slog.New(wrappedHandler{glog.NewSlogHandler(l)}).Error("failed")
+
+	var evt map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &evt); err != nil {
+		t.Fatal(err)
+	}
+	caller, _ := evt["caller"].(string)
+	if !strings.HasSuffix(caller, fmt.Sprintf("slog_test.go:%d", line)) {
+		t.Errorf("expected the slog caller at line %d, got %q", line, caller)
+	}
+}
@@ -0,0 +1,193 @@
+package glog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// SyslogFacilityUser is the default facility (user-level messages)
+const SyslogFacilityUser = 1
+
+// syslog severities as defined in RFC 5424
+const (
+	severityAlert   = 1
+	severityCrit    = 2
+	severityErr     = 3
+	severityWarning = 4
+	severityInfo    = 6
+	severityDebug   = 7
+)
+
+var syslogLocalSockets = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+// SyslogWriter writes the events as RFC 5424 messages to a syslog daemon
+type SyslogWriter struct {
+	// Facility is the syslog facility code, SyslogFacilityUser by default
+	Facility int
+
+	network  string
+	addr     string
+	appName  string
+	hostname string
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// NewSyslogWriter connects to a syslog daemon over "unix", "unixgram", "udp" or "tcp".
+// An empty network and address connects to the local daemon socket (e.g. /dev/log).
+func NewSyslogWriter(network, addr, appName string) (*SyslogWriter, error) {
+	hostname, _ := os.Hostname()
+	if hostname == "" {
+		hostname = "-"
+	}
+	if appName == "" {
+		appName = filepath.Base(os.Args[0])
+	}
+
+	w := &SyslogWriter{
+		Facility: SyslogFacilityUser,
+		network:  network,
+		addr:     addr,
+		appName:  appName,
+		hostname: hostname,
+	}
+	if err := w.connect(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *SyslogWriter) connect() (err error) {
+	if w.conn != nil {
+		_ = w.conn.Close()
+		w.conn = nil
+	}
+
+	if w.network != "" || w.addr != "" {
+		w.conn, err = net.Dial(w.network, w.addr)
+		return err
+	}
+
+	for _, path := range syslogLocalSockets {
+		for _, network := range []string{"unixgram", "unix"} {
+			if w.conn, err = net.Dial(network, path); err == nil {
+				w.network, w.addr = network, path
+				return nil
+			}
+		}
+	}
+	return errors.New("glog: no local syslog socket found")
+}
+
+func (w *SyslogWriter) Write(p []byte) (int, error) {
+	return w.WriteLevel(levelFromEvent(p), p)
+}
+
+// WriteLevel implements zerolog.LevelWriter
+func (w *SyslogWriter) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	msg := w.format(level, p)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	// retry once after reconnecting in case the daemon was restarted
+	var err error
+	for i := 0; i < 2; i++ {
+		if w.conn == nil {
+			if err = w.connect(); err != nil {
+				return 0, err
+			}
+		}
+		if _, err = w.conn.Write(msg); err == nil {
+			return len(p), nil
+		}
+		_ = w.conn.Close()
+		w.conn = nil
+	}
+	return 0, err
+}
+
+// format renders the RFC 5424 message with the event JSON as the MSG part
+func (w *SyslogWriter) format(level zerolog.Level, p []byte) []byte {
+	p = bytes.TrimRight(p, "\n")
+	header := fmt.Sprintf("<%d>1 %s %s %s %d - - ",
+		w.Facility*8+syslogSeverity(level),
+		time.Now().Format("2006-01-02T15:04:05.000000Z07:00"),
+		w.hostname, w.appName, os.Getpid(),
+	)
+
+	msg := make([]byte, 0, len(header)+len(p)+16)
+	switch w.network {
+	case "tcp", "tcp4", "tcp6":
+		// octet counting framing (RFC 6587)
+		msg = append(msg, strconv.Itoa(len(header)+len(p))...)
+		msg = append(msg, ' ')
+		msg = append(msg, header...)
+		msg = append(msg, p...)
+	case "unix":
+		msg = append(msg, header...)
+		msg = append(msg, p...)
+		msg = append(msg, '\n')
+	default:
+		msg = append(msg, header...)
+		msg = append(msg, p...)
+	}
+	return msg
+}
+
+func (w *SyslogWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
+
+func syslogSeverity(level zerolog.Level) int {
+	switch level {
+	case zerolog.TraceLevel, zerolog.DebugLevel:
+		return severityDebug
+	case zerolog.WarnLevel:
+		return severityWarning
+	case zerolog.ErrorLevel:
+		return severityErr
+	case zerolog.FatalLevel:
+		return severityCrit
+	case zerolog.PanicLevel:
+		return severityAlert
+	default:
+		return severityInfo
+	}
+}
+
+// levelFromEvent reads the level of a zerolog JSON event
+func levelFromEvent(p []byte) zerolog.Level {
+	key := []byte(`"` + zerolog.LevelFieldName + `":"`)
+	i := bytes.Index(p, key)
+	if i < 0 {
+		return zerolog.NoLevel
+	}
+	rest := p[i+len(key):]
+	j := bytes.IndexByte(rest, '"')
+	if j < 0 {
+		return zerolog.NoLevel
+	}
+	level, err := zerolog.ParseLevel(string(rest[:j]))
+	if err != nil {
+		return zerolog.NoLevel
+	}
+	return level
+}
@@ -0,0 +1,137 @@
+//go:build unix
+
+package glog_test
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	glog "github.com/omgolab/go-commons/pkg/log"
+)
+
+var syslogLine = regexp.MustCompile(`^<(\d+)>1 \S+ \S+ myapp \d+ - - (\{.*\})$`)
+
+func TestSyslogWriter(t *testing.T) {
+	udp, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer udp.Close()
+
+	tcp, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tcp.Close()
+
+	unixPath := filepath.Join(t.TempDir(), "syslog.sock")
+	unixgram, err := net.ListenPacket("unixgram", unixPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unixgram.Close()
+
+	readPacket := func(pc net.PacketConn) func() string {
+		return func() string {
+			buf := make([]byte, 4096)
+			_ = pc.SetReadDeadline(time.Now().Add(2 * time.Second))
+			n, _, err := pc.ReadFrom(buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return string(buf[:n])
+		}
+	}
+	readFrame := func() string {
+		conn, err := tcp.Accept()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+		_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		r := bufio.NewReader(conn)
+		size, err := r.ReadString(' ')
+		if err != nil {
+			t.Fatal(err)
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(size))
+		if err != nil {
+			t.Fatal(err)
+		}
+		buf := make([]byte, n)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			t.Fatal(err)
+		}
+		return string(buf)
+	}
+
+	tests := []struct {
+		network, addr string
+		read          func() string
+	}{
+		{"udp", udp.LocalAddr().String(), readPacket(udp)},
+		{"unixgram", unixPath, readPacket(unixgram)},
+		{"tcp", tcp.Addr().String(), readFrame},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.network, func(t *testing.T) {
+			l, err := glog.New(glog.WithWriters(), glog.WithSyslog(tt.network, tt.addr, "myapp"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			l.Warn("disk low")
+			m := syslogLine.FindStringSubmatch(tt.read())
+			if m == nil {
+				t.Fatalf("invalid syslog message")
+			}
+			// user facility (1) * 8 + warning (4)
+			if m[1] != "12" || !strings.Contains(m[2], `"message":"disk low"`) {
+				t.Errorf("unexpected syslog message: %v", m)
+			}
+		})
+	}
+}
+
+func TestJournaldWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.sock")
+	pc, err := net.ListenPacket("unixgram", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	l, err := glog.New(glog.WithWriters(), glog.WithJournald(path, "myapp"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Error("multi\nline", nil, glog.LogFields{"user-id": 7})
+
+	buf := make([]byte, 4096)
+	_ = pc.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := string(buf[:n])
+	want := []string{
+		"MESSAGE\n\x0a\x00\x00\x00\x00\x00\x00\x00multi\nline\n",
+		"PRIORITY=3\n",
+		"SYSLOG_IDENTIFIER=myapp\n",
+		"USER_ID=7\n",
+	}
+	for _, w := range want {
+		if !strings.Contains(got, w) {
+			t.Errorf("expected %q in %q", w, got)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+// Package glogtest provides a glog.Logger recording structured entries for assertions in tests.
+package glogtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	glog "github.com/omgolab/go-commons/pkg/log"
+	"github.com/rs/zerolog"
+)
+
+// Entry is a single recorded log event
+type Entry struct {
+	Level   glog.LogLevel
+	Message string
+	Fields  glog.LogFields
+	Error   string
+	Caller  string
+	Time    time.Time
+	Raw     string
+}
+
+// Logger is a glog.Logger recording every event it writes
+type Logger struct {
+	glog.Logger
+	mu        sync.RWMutex
+	entries   []Entry
+	exitCodes []int
+}
+
+// New returns a recording logger attaching the caller to all levels.
+// Fatal doesn't exit but records the exit code (see ExitCodes).
+// If the test fails, the recorded entries are written to t.Log.
+func New(t testing.TB, opts ...glog.LogOption) *Logger {
+	t.Helper()
+
+	l := &Logger{}
+	opts = append([]glog.LogOption{
+		glog.WithWriters(l),
+		glog.WithDefaultLogLevel(glog.TraceLevel),
+		glog.WithExitFunc(l.recordExit),
+	}, opts...)
+	base, err := glog.New(opts...)
+	if err != nil {
+		t.Fatalf("glogtest: %v", err)
+	}
+	l.Logger = base.SetMinCallerAttachLevel(glog.TraceLevel)
+
+	t.Cleanup(func() {
+		if !t.Failed() {
+			return
+		}
+		for _, e := range l.Entries() {
+			t.Log(strings.TrimSpace(e.Raw))
+		}
+	})
+	return l
+}
+
+// Write implements io.Writer by decoding the zerolog JSON event into an Entry
+func (l *Logger) Write(b []byte) (int, error) {
+	var evt map[string]any
+	if err := json.Unmarshal(b, &evt); err != nil {
+		return 0, err
+	}
+
+	e := Entry{Raw: string(b), Fields: glog.LogFields{}}
+	for k, v := range evt {
+		s, _ := v.(string)
+		switch k {
+		case zerolog.LevelFieldName:
+			e.Level, _ = glog.ParseLogLevel(s)
+		case zerolog.MessageFieldName:
+			e.Message = s
+		case zerolog.ErrorFieldName:
+			e.Error = s
+		case zerolog.CallerFieldName:
+			e.Caller = s
+		case zerolog.TimestampFieldName:
+			e.Time, _ = time.Parse(zerolog.TimeFieldFormat, s)
+		default:
+			e.Fields[k] = v
+		}
+	}
+
+	l.mu.Lock()
+	l.entries = append(l.entries, e)
+	l.mu.Unlock()
+	return len(b), nil
+}
+
+func (l *Logger) recordExit(code int) {
+	l.mu.Lock()
+	l.exitCodes = append(l.exitCodes, code)
+	l.mu.Unlock()
+}
+
+// ExitCodes returns the exit codes Fatal would have exited with
+func (l *Logger) ExitCodes() []int {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return append([]int{}, l.exitCodes...)
+}
+
+// Entries returns a copy of the recorded entries
+func (l *Logger) Entries() []Entry {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return append([]Entry{}, l.entries...)
+}
+
+// Reset drops the recorded entries and exit codes
+func (l *Logger) Reset() {
+	l.mu.Lock()
+	l.entries = nil
+	l.exitCodes = nil
+	l.mu.Unlock()
+}
+
+// FindLogged returns the entries with the level, containing msgSubstring and all the fields
+func (l *Logger) FindLogged(level glog.LogLevel, msgSubstring string, fields glog.LogFields) []Entry {
+	var found []Entry
+	for _, e := range l.Entries() {
+		if e.Level == level && strings.Contains(e.Message, msgSubstring) && hasFields(e.Fields, fields) {
+			found = append(found, e)
+		}
+	}
+	return found
+}
+
+// AssertLogged fails the test if no entry has the level, contains msgSubstring and all the fields
+func (l *Logger) AssertLogged(t testing.TB, level glog.LogLevel, msgSubstring string, fields glog.LogFields) {
+	t.Helper()
+	if len(l.FindLogged(level, msgSubstring, fields)) == 0 {
+		t.Errorf("glogtest: expected a %s entry containing %q with fields %v", level, msgSubstring, fields)
+	}
+}
+
+// AssertNotLogged fails the test if any entry has the level, contains msgSubstring and all the fields
+func (l *Logger) AssertNotLogged(t testing.TB, level glog.LogLevel, msgSubstring string, fields glog.LogFields) {
+	t.Helper()
+	if found := l.FindLogged(level, msgSubstring, fields); len(found) > 0 {
+		t.Errorf("glogtest: expected no %s entry containing %q, found %d", level, msgSubstring, len(found))
+	}
+}
+
+// AssertNoErrors fails the test if any entry has the error level or above, or carries an error
+func (l *Logger) AssertNoErrors(t testing.TB) {
+	t.Helper()
+	for _, e := range l.Entries() {
+		if e.Level >= glog.ErrorLevel || e.Error != "" {
+			t.Errorf("glogtest: unexpected %s entry %q: %s", e.Level, e.Message, e.Error)
+		}
+	}
+}
+
+// hasFields compares the expected fields by their JSON representation
+func hasFields(got, want glog.LogFields) bool {
+	for k, v := range want {
+		gv, ok := got[k]
+		if !ok || !reflect.DeepEqual(gv, normalize(v)) {
+			return false
+		}
+	}
+	return true
+}
+
+func normalize(v any) any {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	var n any
+	if err := json.Unmarshal(b, &n); err != nil {
+		return fmt.Sprint(v)
+	}
+	return n
+}
@@ -0,0 +1,58 @@
+package glogtest_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	glog "github.com/omgolab/go-commons/pkg/log"
+	glogtest "github.com/omgolab/go-commons/pkg/log/test"
+)
+
+// fakeTB records the failures instead of failing the test, the other methods go to the embedded TB
+type fakeTB struct {
+	testing.TB
+	errors []string
+}
+
+func (f *fakeTB) Helper() {}
+
+func (f *fakeTB) Errorf(format string, args ...any) {
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+}
+
+func TestLogger_RecordsEntries(t *testing.T) {
+	l := glogtest.New(t)
+
+	l.Info("user created", glog.LogFields{"id": 7, "tags": []string{"a"}})
+	l.Error("save failed", errors.New("disk full"))
+
+	entries := l.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	if e := entries[1]; e.Level != glog.ErrorLevel || e.Error != "disk full" || e.Caller == "" || e.Time.IsZero() {
+		t.Errorf("unexpected entry: %+v", e)
+	}
+
+	l.AssertLogged(t, glog.InfoLevel, "created", glog.LogFields{"id": 7, "tags": []string{"a"}})
+	l.AssertNotLogged(t, glog.InfoLevel, "created", glog.LogFields{"id": 8})
+	l.AssertNotLogged(t, glog.DebugLevel, "created", nil)
+}
+
+func TestLogger_AssertionsReportFailures(t *testing.T) {
+	l := glogtest.New(t)
+	l.Warn("careful")
+	l.Error("broken", errors.New("boom"))
+
+	ft := &fakeTB{TB: t}
+	l.AssertLogged(ft, glog.WarnLevel, "missing", nil)
+	l.AssertNoErrors(ft)
+	if len(ft.errors) != 2 {
+		t.Errorf("expected the assertions to fail twice, got %q", ft.errors)
+	}
+
+	l.Reset()
+	l.Info("fine")
+	l.AssertNoErrors(t)
+}
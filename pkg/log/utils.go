@@ -5,6 +5,17 @@ import (
 	"fmt"
 )
 
+const (
+	fieldsCtxKey    LogStr = "fields"
+	traceIDCtxKey   LogStr = "trace-id"
+	spanIDCtxKey    LogStr = "span-id"
+	requestIDCtxKey LogStr = "request-id"
+)
+
+// ContextExtractor returns the fields found in the context that should be
+// attached to every log line written with a context-aware method
+type ContextExtractor func(ctx context.Context) LogFields
+
 // LoggerToContext returns a context with the attached logger
 func LoggerToContext[T Logger](parentCtx context.Context, l T) context.Context {
 	return context.WithValue(parentCtx, LogStr("logger"), l)
@@ -19,3 +30,79 @@ func ContextToLogger[T any](ctx context.Context) (T, error) {
 
 	return l, fmt.Errorf("logger not found")
 }
+
+// FieldsToContext returns a context carrying the given request-scoped fields
+// merged on top of the fields already attached to the parent context
+func FieldsToContext(parentCtx context.Context, fields LogFields) context.Context {
+	merged := LogFields{}
+	for k, v := range ContextToFields(parentCtx) {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+	return context.WithValue(parentCtx, fieldsCtxKey, merged)
+}
+
+// ContextToFields returns the request-scoped fields attached to the context
+func ContextToFields(ctx context.Context) LogFields {
+	if ctx == nil {
+		return nil
+	}
+	f, _ := ctx.Value(fieldsCtxKey).(LogFields)
+	return f
+}
+
+// TraceToContext returns a context with the attached trace and span IDs
+func TraceToContext(parentCtx context.Context, traceID, spanID string) context.Context {
+	ctx := context.WithValue(parentCtx, traceIDCtxKey, traceID)
+	return context.WithValue(ctx, spanIDCtxKey, spanID)
+}
+
+// RequestIDToContext returns a context with the attached request ID
+func RequestIDToContext(parentCtx context.Context, requestID string) context.Context {
+	return context.WithValue(parentCtx, requestIDCtxKey, requestID)
+}
+
+// FieldsExtractor extracts the request-scoped fields set by FieldsToContext
+func FieldsExtractor(ctx context.Context) LogFields {
+	return ContextToFields(ctx)
+}
+
+// TraceExtractor extracts the trace and span IDs set by TraceToContext
+func TraceExtractor(ctx context.Context) LogFields {
+	f := LogFields{}
+	if v, ok := ctx.Value(traceIDCtxKey).(string); ok && v != "" {
+		f[string(traceIDCtxKey)] = v
+	}
+	if v, ok := ctx.Value(spanIDCtxKey).(string); ok && v != "" {
+		f[string(spanIDCtxKey)] = v
+	}
+	return f
+}
+
+// RequestIDExtractor extracts the request ID set by RequestIDToContext
+func RequestIDExtractor(ctx context.Context) LogFields {
+	if v, ok := ctx.Value(requestIDCtxKey).(string); ok && v != "" {
+		return LogFields{string(requestIDCtxKey): v}
+	}
+	return nil
+}
+
+// extractContextFields runs all the extractors and returns the merged fields
+func extractContextFields(ctx context.Context, extractors []ContextExtractor) LogFields {
+	if ctx == nil || len(extractors) == 0 {
+		return nil
+	}
+
+	var merged LogFields
+	for _, extract := range extractors {
+		for k, v := range extract(ctx) {
+			if merged == nil {
+				merged = LogFields{}
+			}
+			merged[k] = v
+		}
+	}
+	return merged
+}
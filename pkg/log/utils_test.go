@@ -1,7 +1,9 @@
 package glog_test
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"testing"
 
 	glog "github.com/omgolab/go-commons/pkg/log"
@@ -21,3 +23,42 @@ func TestContextToLogger_ReturnsLoggerIfAvailable(t *testing.T) {
 		t.Errorf("Expected logger to be StringLogger, but got: %v", logger)
 	}
 }
+
+// Adds the request-scoped, trace and custom extracted fields to the ctx log lines.
+func TestInfoCtx_AddsContextFields(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l, err := glog.New(
+		glog.WithMultiLogger(buf),
+		glog.WithContextExtractors(func(ctx context.Context) glog.LogFields {
+			return glog.LogFields{"tenant": "acme"}
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := glog.TraceToContext(context.Background(), "trace-1", "span-1")
+	ctx = glog.RequestIDToContext(ctx, "req-1")
+	ctx = glog.FieldsToContext(ctx, glog.LogFields{"user": "u1"})
+	ctx = glog.FieldsToContext(ctx, glog.LogFields{"route": "/x"})
+	l.InfoCtx(ctx, "hello", glog.LogFields{"user": "u2"})
+
+	var got map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{
+		"trace-id":   "trace-1",
+		"span-id":    "span-1",
+		"request-id": "req-1",
+		"route":      "/x",
+		"user":       "u2",
+		"tenant":     "acme",
+		"message":    "hello",
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("field %q: expected %v, got %v", k, v, got[k])
+		}
+	}
+}